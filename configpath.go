@@ -0,0 +1,99 @@
+package easyconfig
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// ConfigPathSource loads the config files whose paths are given on start: the -config flag,
+// <PREFIX>_CONFIG environment variable, the Config field of the struct or Default, in this order.
+// Field changes the name of the designated field (its flag and env names are used), the field
+// is set to the loaded paths. Paths are separated by commas and loaded by FileSource in order,
+// sources after ConfigPathSource (EnvSource, FlagsSource) still override the loaded values.
+type ConfigPathSource struct {
+	Prefix  string
+	Field   string
+	Default string
+}
+
+// Load the config files selected by the flag, environment variable or field
+func (s ConfigPathSource) Load(structPtr interface{}) error {
+	paths, explicit := s.paths(structPtr)
+	if paths == "" {
+		return nil
+	}
+	for _, path := range strings.Split(paths, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) && !explicit {
+			continue
+		}
+		if err := (FileSource{Path: path}).Load(structPtr); err != nil {
+			return err
+		}
+	}
+	if field, ok := s.field(structPtr); ok && field.Kind() == reflect.String {
+		field.SetString(paths)
+	}
+	return nil
+}
+
+// paths returns the configured paths and whether they were set explicitly (not by Default)
+func (s ConfigPathSource) paths(structPtr interface{}) (string, bool) {
+	flagName, envName := s.names(structPtr)
+	args := parseArgs(os.Args[1:])
+	if path := args[flagName]; path != "" {
+		return path, true
+	}
+	if path := args["-"+flagName]; path != "" {
+		return path, true
+	}
+	if path := os.Getenv(envName); path != "" {
+		return path, true
+	}
+	if field, ok := s.field(structPtr); ok && field.Kind() == reflect.String && field.String() != "" {
+		return field.String(), true
+	}
+	return s.Default, false
+}
+
+// names returns the flag and environment variable names of the designated field
+func (s ConfigPathSource) names(structPtr interface{}) (flagName, envName string) {
+	name := s.fieldName()
+	flagTag, envTag := "", ""
+	if structPtr != nil {
+		if field, ok := reflect.ValueOf(structPtr).Elem().Type().FieldByName(name); ok {
+			flagTag = strings.TrimSpace(field.Tag.Get("flag"))
+			envTag = strings.TrimSpace(field.Tag.Get("env"))
+		}
+	}
+	flagName, _ = convertName(name, "flag", flagTag, "")
+	envName, _ = convertName(name, "env", envTag, s.Prefix)
+	return flagName, envName
+}
+
+func (s ConfigPathSource) field(structPtr interface{}) (reflect.Value, bool) {
+	field := reflect.ValueOf(structPtr).Elem().FieldByName(s.fieldName())
+	return field, field.IsValid() && field.CanSet()
+}
+
+func (s ConfigPathSource) fieldName() string {
+	if s.Field != "" {
+		return s.Field
+	}
+	return "Config"
+}
+
+func (s ConfigPathSource) help(structPtr interface{}) {
+	flagName, envName := s.names(structPtr)
+	fmt.Printf("\nConfiguration file (%s or %s):\n\n", flagName, envName)
+	paths, _ := s.paths(structPtr)
+	if paths == "" {
+		paths = "none"
+	}
+	bold.Printf("    %s\n", paths)
+}
@@ -0,0 +1,419 @@
+package easyconfig
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"filippo.io/age"
+)
+
+const (
+	// EncryptAESGCM encrypts values with a base64 encoded 32-byte AES-256 key
+	EncryptAESGCM = "aes-gcm"
+	// EncryptAge encrypts values to an age X25519 recipient (age1...),
+	// decryption uses the matching identity (AGE-SECRET-KEY-1...)
+	EncryptAge = "age"
+
+	ErrNoKey             strErr = "no key to decrypt ENC[...] value, set EASYCONFIG_KEY or EASYCONFIG_KEY_FILE"
+	ErrDecrypt           strErr = "unable to decrypt ENC[...] value"
+	ErrUnknownEncryption strErr = "unknown encryption method"
+	ErrInvalidEncrypted  strErr = "invalid ENC[...] value"
+)
+
+var (
+	// KeyEnv is the environment variable with keys used to decrypt ENC[...] values:
+	// base64 encoded AES-256 keys and/or age identities, one per line.
+	KeyEnv = "EASYCONFIG_KEY"
+	// KeyFileEnv is the environment variable with the path of a file with keys
+	KeyFileEnv = "EASYCONFIG_KEY_FILE"
+)
+
+type keyRing struct {
+	loaded     bool
+	err        error
+	aesKeys    [][]byte
+	identities []age.Identity
+}
+
+// Encrypt returns the value encrypted as ENC[method,base64 data]. The key is a base64
+// encoded 32-byte key for EncryptAESGCM or an age recipient for EncryptAge.
+func Encrypt(method, key, value string) (string, error) {
+	var data []byte
+	switch method {
+	case EncryptAESGCM:
+		aesKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+		if err != nil {
+			return "", err
+		}
+		gcm, err := newGCM(aesKey)
+		if err != nil {
+			return "", err
+		}
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		data = gcm.Seal(nonce, nonce, []byte(value), nil)
+	case EncryptAge:
+		recipient, err := age.ParseX25519Recipient(strings.TrimSpace(key))
+		if err != nil {
+			return "", err
+		}
+		buf := new(bytes.Buffer)
+		w, err := age.Encrypt(buf, recipient)
+		if err != nil {
+			return "", err
+		}
+		if _, err := io.WriteString(w, value); err != nil {
+			return "", err
+		}
+		if err := w.Close(); err != nil {
+			return "", err
+		}
+		data = buf.Bytes()
+	default:
+		return "", ErrUnknownEncryption
+	}
+	return fmt.Sprintf("ENC[%s,%s]", method, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// EncryptFile replaces the file content with a single ENC[...] value,
+// file sources decrypt it transparently before decoding.
+func EncryptFile(path, method, key string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	encrypted, err := Encrypt(method, key, string(data))
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(encrypted+"\n"), info.Mode().Perm())
+}
+
+// GenerateKey returns a new base64 encoded key for EncryptAESGCM
+func GenerateKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, "ENC[") && strings.HasSuffix(value, "]")
+}
+
+// decrypt returns the plain value of ENC[...] or the value itself
+func (k *keyRing) decrypt(value string) (string, error) {
+	if !isEncrypted(value) {
+		return value, nil
+	}
+	p := strings.SplitN(value[4:len(value)-1], ",", 2)
+	if len(p) != 2 {
+		return "", ErrInvalidEncrypted
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(p[1]))
+	if err != nil {
+		return "", ErrInvalidEncrypted
+	}
+	if err := k.load(); err != nil {
+		return "", err
+	}
+
+	switch p[0] {
+	case EncryptAESGCM:
+		for _, key := range k.aesKeys {
+			gcm, err := newGCM(key)
+			if err != nil || len(data) < gcm.NonceSize() {
+				continue
+			}
+			nonce := data[:gcm.NonceSize()]
+			if plain, err := gcm.Open(nil, nonce, data[gcm.NonceSize():], nil); err == nil {
+				return string(plain), nil
+			}
+		}
+	case EncryptAge:
+		if len(k.identities) > 0 {
+			if r, err := age.Decrypt(bytes.NewReader(data), k.identities...); err == nil {
+				if plain, err := ioutil.ReadAll(r); err == nil {
+					return string(plain), nil
+				}
+			}
+		}
+	default:
+		return "", ErrUnknownEncryption
+	}
+	return "", ErrDecrypt
+}
+
+// load reads keys from KeyEnv and KeyFileEnv on the first use
+func (k *keyRing) load() error {
+	if k.loaded {
+		return k.err
+	}
+	k.loaded = true
+	keys := os.Getenv(KeyEnv)
+	if path := os.Getenv(KeyFileEnv); path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			k.err = err
+			return err
+		}
+		keys += "\n" + string(data)
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(keys))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "AGE-SECRET-KEY-"):
+			identity, err := age.ParseX25519Identity(line)
+			if err != nil {
+				k.err = err
+				return err
+			}
+			k.identities = append(k.identities, identity)
+		default:
+			key, err := base64.StdEncoding.DecodeString(line)
+			if err != nil {
+				k.err = fmt.Errorf("%s: invalid key: %w", KeyEnv, err)
+				return k.err
+			}
+			k.aesKeys = append(k.aesKeys, key)
+		}
+	}
+	if len(k.aesKeys) == 0 && len(k.identities) == 0 {
+		k.err = ErrNoKey
+	}
+	return k.err
+}
+
+// decryptMap decrypts ENC[...] values of file=value and env maps
+func decryptMap(mp map[string]string) error {
+	keys := new(keyRing)
+	for key, value := range mp {
+		plain, err := keys.decrypt(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if plain != strings.TrimSpace(value) {
+			mp[key] = plain
+		}
+	}
+	return nil
+}
+
+// decryptEncoded decrypts ENC[...] values of the document before it is bound to the struct: the document
+// is decoded with unmarshal into maps, lists and scalars, decrypted and encoded back with marshal.
+// Data is returned as is without ENC[...] values. Paths of the decrypted fields are returned.
+func decryptEncoded(data []byte, structPtr interface{}, tag string, unmarshal func([]byte) (interface{}, error), marshal func(interface{}) ([]byte, error)) ([]byte, map[string]bool, error) {
+	if !bytes.Contains(data, []byte("ENC[")) {
+		return data, nil, nil
+	}
+	doc, err := unmarshal(data)
+	if err != nil {
+		// syntax errors are reported by the decoder of the struct
+		return data, nil, nil
+	}
+	decrypted := map[string]bool{}
+	if doc, err = decryptDocument(new(keyRing), doc, reflect.TypeOf(structPtr).Elem(), tag, nil, decrypted); err != nil {
+		return nil, nil, err
+	}
+	if len(decrypted) == 0 {
+		return data, nil, nil
+	}
+	data, err = marshal(doc)
+	return data, decrypted, err
+}
+
+// decryptDocument decrypts ENC[...] strings of the decoded document, values of numeric and bool fields
+// get their type. Paths of the decrypted fields are added to decrypted.
+func decryptDocument(keys *keyRing, value interface{}, structType reflect.Type, tag string, path []string, decrypted map[string]bool) (interface{}, error) {
+	var err error
+	switch v := value.(type) {
+	case string:
+		if !isEncrypted(v) {
+			return v, nil
+		}
+		plain, err := keys.decrypt(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", strings.Join(path, "."), err)
+		}
+		kind, fieldPath := documentField(structType, tag, path)
+		decrypted[fieldPath] = true
+		return typedValue(plain, kind), nil
+	case map[string]interface{}:
+		for key, item := range v {
+			if v[key], err = decryptDocument(keys, item, structType, tag, append(path[:len(path):len(path)], key), decrypted); err != nil {
+				return nil, err
+			}
+		}
+	case map[interface{}]interface{}:
+		for key, item := range v {
+			name := strings.TrimPrefix(fmt.Sprint(key), ":")
+			if v[key], err = decryptDocument(keys, item, structType, tag, append(path[:len(path):len(path)], name), decrypted); err != nil {
+				return nil, err
+			}
+		}
+	case []map[string]interface{}:
+		for _, item := range v {
+			if _, err = decryptDocument(keys, item, structType, tag, path, decrypted); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if v[i], err = decryptDocument(keys, item, structType, tag, path, decrypted); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return value, nil
+}
+
+// documentField returns the kind and the path of the struct field the document path binds to,
+// reflect.String when there is no such field. Map values and list items have the kind of their elements.
+func documentField(structType reflect.Type, tag string, path []string) (reflect.Kind, string) {
+	t := structType
+	fieldPath := []string{}
+	for _, name := range path {
+		t = elemType(t)
+		switch t.Kind() {
+		case reflect.Map:
+			t = t.Elem()
+		case reflect.Struct:
+			i, _, ok := fieldIndex(t, tag, name)
+			if !ok {
+				return reflect.String, strings.Join(append(fieldPath, name), ".")
+			}
+			fieldPath = append(fieldPath, t.Field(i).Name)
+			t = t.Field(i).Type
+		default:
+			return reflect.String, strings.Join(fieldPath, ".")
+		}
+	}
+	return elemType(t).Kind(), strings.Join(fieldPath, ".")
+}
+
+// elemType returns the element type of pointers, slices and arrays
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t
+}
+
+// typedValue converts the decrypted value to the number or bool of the field kind, strings are kept
+func typedValue(plain string, kind reflect.Kind) interface{} {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(plain, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseUint(plain, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(plain, 64); err == nil {
+			return f
+		}
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(plain); err == nil {
+			return b
+		}
+	}
+	return plain
+}
+
+// decryptStruct decrypts ENC[...] values left in string fields, slices, maps and nested structs after
+// the file is decoded (formats without document level decryption). Paths of the decrypted fields are returned
+func decryptStruct(structPtr interface{}) (map[string]bool, error) {
+	decrypted := map[string]bool{}
+	return decrypted, decryptValue(new(keyRing), "", reflect.ValueOf(structPtr).Elem(), decrypted)
+}
+
+func decryptValue(keys *keyRing, name string, value reflect.Value, decrypted map[string]bool) error {
+	switch value.Kind() {
+	case reflect.String:
+		if value.CanSet() && isEncrypted(value.String()) {
+			plain, err := keys.decrypt(value.String())
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			value.SetString(plain)
+			decrypted[name] = true
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := decryptValue(keys, name, value.Index(i), decrypted); err != nil {
+				return err
+			}
+		}
+	case reflect.Ptr:
+		if !value.IsNil() {
+			return decryptValue(keys, name, value.Elem(), decrypted)
+		}
+	case reflect.Interface:
+		if !value.IsNil() && value.CanSet() {
+			// values in interfaces are not settable, decrypt a copy and store it back
+			elem := reflect.New(value.Elem().Type()).Elem()
+			elem.Set(value.Elem())
+			if err := decryptValue(keys, name, elem, decrypted); err != nil {
+				return err
+			}
+			value.Set(elem)
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			// map values are not addressable, decrypt a copy and store it back
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+			if err := decryptValue(keys, name, elem, decrypted); err != nil {
+				return err
+			}
+			value.SetMapIndex(iter.Key(), elem)
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			fieldName := field.Name
+			if name != "" {
+				fieldName = name + "." + field.Name
+			}
+			if err := decryptValue(keys, fieldName, value.Field(i), decrypted); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
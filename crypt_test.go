@@ -0,0 +1,150 @@
+package easyconfig
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func TestEncryptedValues(t *testing.T) {
+	aesKey, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv(KeyEnv, aesKey+"\n"+identity.String())
+	defer os.Unsetenv(KeyEnv)
+
+	password, err := Encrypt(EncryptAESGCM, aesKey, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := Encrypt(EncryptAge, identity.Recipient().String(), "postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	t.Run("YAMLSource.Load", func(t *testing.T) {
+		path := filepath.Join(dir, "config.yaml")
+		data := fmt.Sprintf("postgresUser: %s\npostgresPassword: %s\npostgresPort: 5432\n", user, password)
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		config := new(Config)
+		if err := (YAMLSource{path}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "postgres" {
+			t.Errorf("PostgresUser = %s, want %s", config.PostgresUser, "postgres")
+		}
+		if config.PostgresPassword != "password" {
+			t.Errorf("PostgresPassword = %s, want %s", config.PostgresPassword, "password")
+		}
+	})
+
+	t.Run("EnvFileSource.Load", func(t *testing.T) {
+		path := filepath.Join(dir, "config.env")
+		port, _ := Encrypt(EncryptAESGCM, aesKey, "5432")
+		data := fmt.Sprintf("APP_POSTGRES_PASSWORD=%s\nAPP_POSTGRES_PORT=%s\n", password, port)
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		config := new(Config)
+		if err := (EnvFileSource{"APP", path}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresPassword != "password" {
+			t.Errorf("PostgresPassword = %s, want %s", config.PostgresPassword, "password")
+		}
+		if config.PostgresPort != 5432 {
+			t.Errorf("PostgresPort = %d, want %d", config.PostgresPort, 5432)
+		}
+	})
+
+	t.Run("EncryptFile", func(t *testing.T) {
+		path := filepath.Join(dir, "config.json")
+		if err := ioutil.WriteFile(path, []byte(`{"postgresPassword": "password"}`), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := EncryptFile(path, EncryptAge, identity.Recipient().String()); err != nil {
+			t.Fatal(err)
+		}
+		config := new(Config)
+		if err := (JSONSource{path}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresPassword != "password" {
+			t.Errorf("PostgresPassword = %s, want %s", config.PostgresPassword, "password")
+		}
+	})
+
+	t.Run("maps and non-string fields", func(t *testing.T) {
+		type mapConfig struct {
+			Labels  map[string]string      `yaml:"labels"`
+			Extra   map[string]interface{} `yaml:"extra"`
+			Port    int                    `yaml:"port"`
+			Enabled bool                   `yaml:"enabled"`
+		}
+		path := filepath.Join(dir, "maps.yaml")
+		data := fmt.Sprintf("labels:\n  user: %s\nextra:\n  password: %s\n  list:\n    - %s\n", user, password, password)
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		config := new(mapConfig)
+		if err := (YAMLSource{path}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.Labels["user"] != "postgres" {
+			t.Errorf("Labels[user] = %s, want %s", config.Labels["user"], "postgres")
+		}
+		if config.Extra["password"] != "password" || fmt.Sprint(config.Extra["list"]) != "[password]" {
+			t.Errorf("Extra = %v, want %s", config.Extra, "password values")
+		}
+
+	})
+
+	t.Run("typed fields", func(t *testing.T) {
+		port, _ := Encrypt(EncryptAESGCM, aesKey, "5432")
+		enabled, _ := Encrypt(EncryptAge, identity.Recipient().String(), "true")
+		tests := []struct {
+			source fileSource
+			data   string
+		}{
+			{YAMLSource{"config.yaml"}, fmt.Sprintf("postgresPort: %s\nports:\n  - %s\nenabled: \"%s\"\n", port, port, enabled)},
+			{JSONSource{"config.json"}, fmt.Sprintf(`{"postgresPort": "%s", "ports": ["%s"], "enabled": "%s"}`, port, port, enabled)},
+			{TOMLSource{"config.toml"}, fmt.Sprintf("postgresPort = \"%s\"\nports = [\"%s\"]\nenabled = \"%s\"\n", port, port, enabled)},
+			{EDNSource{"config.edn"}, fmt.Sprintf(`{:postgresPort "%s" :ports ["%s"] :enabled "%s"}`, port, port, enabled)},
+		}
+		for _, tt := range tests {
+			config := &struct {
+				PostgresPort uint64 `json:"postgresPort" yaml:"postgresPort" toml:"postgresPort" edn:"postgresPort"`
+				Ports        []int  `json:"ports" yaml:"ports" toml:"ports" edn:"ports"`
+				Enabled      bool   `json:"enabled" yaml:"enabled" toml:"enabled" edn:"enabled"`
+			}{}
+			if err := tt.source.decode(nil, []byte(tt.data), config); err != nil {
+				t.Errorf("%T: Error = %s, want %s", tt.source, err.Error(), "nil")
+				continue
+			}
+			if config.PostgresPort != 5432 || fmt.Sprint(config.Ports) != "[5432]" || !config.Enabled {
+				t.Errorf("%T: config = %+v, want decrypted port and enabled", tt.source, *config)
+			}
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		otherKey, _ := GenerateKey()
+		os.Setenv(KeyEnv, otherKey)
+		config := new(Config)
+		if err := (YAMLSource{filepath.Join(dir, "config.yaml")}).Load(config); err == nil {
+			t.Errorf("Error = nil, want decryption error")
+		}
+	})
+}
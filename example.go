@@ -0,0 +1,222 @@
+package easyconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"olympos.io/encoding/edn"
+)
+
+// WriteExample writes an example configuration built from structPtr to the
+// source path. The format is chosen by the file extension, a path without
+// extension is treated as a configuration directory.
+func (s FileSource) WriteExample(structPtr interface{}) error {
+	switch filepath.Ext(s.Path) {
+	case ".json":
+		return JSONSource{Path: s.Path}.WriteExample(structPtr)
+	case ".yaml", ".yml":
+		return YAMLSource{Path: s.Path}.WriteExample(structPtr)
+	case ".env":
+		return EnvFileSource{Path: s.Path}.WriteExample(structPtr)
+	case ".toml":
+		return TOMLSource{Path: s.Path}.WriteExample(structPtr)
+	case ".edn":
+		return EDNSource{Path: s.Path}.WriteExample(structPtr)
+	case "":
+		return DirSource{Path: s.Path}.WriteExample(structPtr)
+	default:
+		return ErrUnknownFileType
+	}
+}
+
+// WriteExample writes an example JSON configuration built from structPtr
+func (s JSONSource) WriteExample(structPtr interface{}) error {
+	data, err := json.MarshalIndent(exampleValue(structPtr).Interface(), "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.Path, append(data, '\n'), 0644)
+}
+
+// WriteExample writes a commented example YAML configuration built from structPtr
+func (s YAMLSource) WriteExample(structPtr interface{}) error {
+	buf := new(bytes.Buffer)
+	err := eachExampleField(structPtr, func(field reflect.StructField, value reflect.Value) error {
+		data, err := marshalYAML(singleField(field, value))
+		if err != nil || string(data) == "{}\n" {
+			return err
+		}
+		writeComment(buf, "# ", field)
+		buf.Write(data)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.Path, buf.Bytes(), 0644)
+}
+
+// WriteExample writes a commented example TOML configuration built from structPtr
+func (s TOMLSource) WriteExample(structPtr interface{}) error {
+	keys, tables := new(bytes.Buffer), new(bytes.Buffer)
+	err := eachExampleField(structPtr, func(field reflect.StructField, value reflect.Value) error {
+		data := new(bytes.Buffer)
+		if err := toml.NewEncoder(data).Encode(singleField(field, value)); err != nil || data.Len() == 0 {
+			return err
+		}
+		// keys written after a table would belong to it, so tables go last
+		buf := keys
+		if strings.HasPrefix(strings.TrimSpace(data.String()), "[") {
+			buf = tables
+			buf.WriteString("\n")
+		}
+		writeComment(buf, "# ", field)
+		buf.Write(data.Bytes())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.Path, append(keys.Bytes(), tables.Bytes()...), 0644)
+}
+
+// WriteExample writes a commented example EDN configuration built from structPtr
+func (s EDNSource) WriteExample(structPtr interface{}) error {
+	buf := bytes.NewBufferString("{\n")
+	err := eachExampleField(structPtr, func(field reflect.StructField, value reflect.Value) error {
+		data, err := edn.MarshalPPrint(singleField(field, value), nil)
+		if err != nil {
+			return err
+		}
+		data = bytes.TrimSpace(data)
+		data = bytes.TrimSpace(data[1 : len(data)-1])
+		if len(data) == 0 {
+			return nil
+		}
+		writeComment(buf, " ; ", field)
+		fmt.Fprintf(buf, " %s\n", data)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	buf.WriteString("}\n")
+	return ioutil.WriteFile(s.Path, buf.Bytes(), 0644)
+}
+
+// envQuoter escapes double-quoted .env values the way godotenv unescapes them
+var envQuoter = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`, `"`, `\"`, "!", `\!`, "$", `\$`, "`", "\\`")
+
+// WriteExample writes a commented example .env file built from structPtr
+func (s EnvFileSource) WriteExample(structPtr interface{}) error {
+	buf := new(bytes.Buffer)
+	err := eachExampleField(structPtr, func(field reflect.StructField, value reflect.Value) error {
+		tagVal := strings.TrimSpace(field.Tag.Get("env"))
+		if tagVal == "-" || field.Type.Kind() == reflect.Struct {
+			return nil
+		}
+		name, separator := convertName(field.Name, "env", tagVal, s.Prefix)
+		val := valueString(value, separator)
+		if strings.ContainsAny(val, " \t\r\n#'\"\\$`") || strings.IndexFunc(val, unicode.IsControl) >= 0 {
+			val = `"` + envQuoter.Replace(val) + `"`
+		}
+		writeComment(buf, "# ", field)
+		fmt.Fprintf(buf, "%s=%s\n", name, val)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.Path, buf.Bytes(), 0644)
+}
+
+// WriteExample creates the configuration directory layout (file=value) built from structPtr
+func (s DirSource) WriteExample(structPtr interface{}) error {
+	if err := os.MkdirAll(s.Path, 0755); err != nil {
+		return err
+	}
+	return eachExampleField(structPtr, func(field reflect.StructField, value reflect.Value) error {
+		tagVal := strings.TrimSpace(field.Tag.Get("dir"))
+		if tagVal == "-" || field.Type.Kind() == reflect.Struct {
+			return nil
+		}
+		name, separator := convertName(field.Name, "dir", tagVal, "")
+		perm := os.FileMode(0644)
+		if isSecret(field) {
+			perm = 0600
+		}
+		return ioutil.WriteFile(filepath.Join(s.Path, name), []byte(valueString(value, separator)), perm)
+	})
+}
+
+// eachExampleField calls fn for every exported field of the structPtr copy with secrets left blank
+func eachExampleField(structPtr interface{}, fn func(field reflect.StructField, value reflect.Value) error) error {
+	structElem := exampleValue(structPtr)
+	for i := 0; i < structElem.NumField(); i++ {
+		field := structElem.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if err := fn(field, structElem.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exampleValue returns a copy of the struct with secret fields set to zero values
+func exampleValue(structPtr interface{}) reflect.Value {
+	example := copyStruct(structPtr)
+	clearSecrets(example)
+	return example
+}
+
+// singleField wraps the value into a one-field struct keeping the field tags,
+// so every encoder applies its own naming rules.
+func singleField(field reflect.StructField, value reflect.Value) interface{} {
+	single := reflect.New(reflect.StructOf([]reflect.StructField{{
+		Name:      field.Name,
+		Type:      field.Type,
+		Tag:       field.Tag,
+		Anonymous: field.Anonymous,
+	}})).Elem()
+	single.Field(0).Set(value)
+	return single.Interface()
+}
+
+func writeComment(buf *bytes.Buffer, comment string, field reflect.StructField) {
+	description := strings.TrimSpace(field.Tag.Get("description"))
+	if description == "" {
+		return
+	}
+	for _, line := range strings.Split(description, "\n") {
+		buf.WriteString(comment + strings.TrimSpace(line) + "\n")
+	}
+}
+
+// valueString formats the value the way setField and setSlice parse it
+func valueString(value reflect.Value, separator string) string {
+	if value.Kind() == reflect.Slice {
+		if separator == "" {
+			separator = ":"
+		}
+		parts := make([]string, value.Len())
+		for i := range parts {
+			parts[i] = valueString(value.Index(i), separator)
+		}
+		return strings.Join(parts, separator)
+	}
+	if value.Kind() == reflect.String {
+		// raw value, Stringer types like Secret would be masked by fmt
+		return value.String()
+	}
+	return fmt.Sprint(value.Interface())
+}
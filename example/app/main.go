@@ -9,7 +9,7 @@ import (
 type (
 	Config struct {
 		PostgresUser     string   `json:"postgresUser" yaml:"postgresUser" dir:"postgres-user" env:"APP_POSTGRES_USER"`
-		PostgresPassword string   `json:"postgresPassword" yaml:"postgresPassword"`
+		PostgresPassword string   `json:"postgresPassword" yaml:"postgresPassword" secret:"true"` // masked by easyconfig.Redact and -help
 		PostgresHost     string   `json:"postgresHost" yaml:"postgresHost"`
 		PostgresPort     uint64   `json:"postgresPort" yaml:"postgresPort"`
 		PostgresDBName   string   `json:"postgresDBName" yaml:"postgresDBName"`
@@ -46,6 +46,6 @@ func main() {
 		},
 	)
 
-	loader.Load(config) // collect data from each source
-	fmt.Printf("%v\n", config)
+	loader.Load(config)                           // collect data from each source
+	fmt.Printf("%v\n", easyconfig.Redact(config)) // secret values are masked
 }
@@ -0,0 +1,87 @@
+package easyconfig
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type (
+	ExampleConfig struct {
+		PostgresUser     string   `json:"postgresUser" yaml:"postgresUser" toml:"postgresUser" description:"Database user"`
+		PostgresPassword string   `json:"postgresPassword" yaml:"postgresPassword" toml:"postgresPassword" secret:"true"`
+		PostgresPort     uint64   `json:"postgresPort" yaml:"postgresPort" toml:"postgresPort"`
+		Slice            []string `json:"slice" yaml:"slice" toml:"slice" env:"slice,:"`
+	}
+)
+
+func TestWriteExample(t *testing.T) {
+	defaults := &ExampleConfig{
+		PostgresUser:     "postgres",
+		PostgresPassword: "password",
+		PostgresPort:     5432,
+		Slice:            []string{"a1", "a2"},
+	}
+	dir := t.TempDir()
+
+	for _, src := range []interface {
+		Source
+		WriteExample(structPtr interface{}) error
+	}{
+		JSONSource{filepath.Join(dir, "config.example.json")},
+		YAMLSource{filepath.Join(dir, "config.example.yaml")},
+		TOMLSource{filepath.Join(dir, "config.example.toml")},
+		EDNSource{filepath.Join(dir, "config.example.edn")},
+		EnvFileSource{"APP", filepath.Join(dir, ".env.example")},
+		DirSource{filepath.Join(dir, "k8s")},
+	} {
+		t.Run(fmt.Sprintf("%T.WriteExample", src), func(t *testing.T) {
+			if err := src.WriteExample(defaults); err != nil {
+				t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+			}
+			config := new(ExampleConfig)
+			if err := src.Load(config); err != nil {
+				t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+			}
+			if config.PostgresUser != "postgres" {
+				t.Errorf("PostgresUser = %s, want %s", config.PostgresUser, "postgres")
+			}
+			if config.PostgresPassword != "" {
+				t.Errorf("PostgresPassword = %s, want %s", config.PostgresPassword, "")
+			}
+			if config.PostgresPort != 5432 {
+				t.Errorf("PostgresPort = %d, want %d", config.PostgresPort, 5432)
+			}
+			if fmt.Sprintf("%v", config.Slice) != "[a1 a2]" {
+				t.Errorf("Slice = %v, want %s", config.Slice, "[a1 a2]")
+			}
+		})
+	}
+
+	t.Run("EnvFileSource quoting", func(t *testing.T) {
+		value := "héllo wörld \u2603\ttab\x01 \"q\" 'a' \\n \\ $HOME ${X} \\$Y `cmd` !# end\nline\r"
+		src := EnvFileSource{"APP", filepath.Join(dir, ".env.quoted")}
+		if err := src.WriteExample(&ExampleConfig{PostgresUser: value}); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		config := new(ExampleConfig)
+		if err := src.Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != value {
+			t.Errorf("PostgresUser = %q, want %q", config.PostgresUser, value)
+		}
+	})
+
+	t.Run("description comments", func(t *testing.T) {
+		data, err := ioutil.ReadFile(filepath.Join(dir, ".env.example"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "# Database user\nAPP_POSTGRES_USER=postgres\n") {
+			t.Errorf(".env.example = %q, want description comment", data)
+		}
+	})
+}
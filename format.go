@@ -0,0 +1,117 @@
+package easyconfig
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type (
+	// Decoder decodes the file content into the struct
+	Decoder func(data []byte, structPtr interface{}) error
+
+	// Sniffer reports whether the content looks like the format
+	Sniffer func(data []byte) bool
+
+	fileFormat struct {
+		name        string
+		exts        []string
+		aliases     []string // other names of the format in shebang and modeline hints
+		decoder     Decoder
+		sniffer     Sniffer
+		fileDecoder func(path string, data []byte, structPtr interface{}) error // built-in formats reporting the file name
+	}
+)
+
+var (
+	formatsMu sync.RWMutex
+	formats   []fileFormat
+)
+
+func init() {
+	registerFormat(fileFormat{name: "yaml", exts: []string{".yaml", ".yml"}, aliases: []string{"yml"}, decoder: decodeYAML, sniffer: sniffYAML})
+	RegisterFormat("toml", []string{".toml"}, decodeTOML, sniffTOML)
+	registerFormat(fileFormat{name: "env", exts: []string{".env"}, aliases: []string{"dotenv", "sh"}, decoder: func(data []byte, structPtr interface{}) error {
+		return decodeEnv("", data, structPtr)
+	}, sniffer: sniffEnv})
+	registerFormat(fileFormat{name: "edn", exts: []string{".edn"}, aliases: []string{"clojure"}, decoder: decodeEDN, sniffer: sniffEDN})
+	RegisterFormat("json", []string{".json"}, decodeJSON, sniffJSON)
+}
+
+// RegisterFormat adds the file format used by FileSource, ConfDirSource and other file sources:
+// exts are file extensions (".ini"), sniffer detects the format of files with unknown extension,
+// sniffers of formats registered later are tried first. Formats without a sniffer are used only
+// by extension or FileSource.Format. Registering an existing name replaces the format.
+func RegisterFormat(name string, exts []string, decoder Decoder, sniffer Sniffer) {
+	registerFormat(fileFormat{name: strings.ToLower(name), exts: exts, decoder: decoder, sniffer: sniffer})
+}
+
+func registerFormat(format fileFormat) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	for i := range formats {
+		if formats[i].name == format.name {
+			formats[i] = format
+			return
+		}
+	}
+	formats = append(formats, format)
+}
+
+// Formats returns names of the registered formats with their extensions, "yaml (.yaml, .yml)"
+func Formats() []string {
+	names := []string{}
+	for _, format := range registeredFormats() {
+		names = append(names, format.name+" ("+strings.Join(format.exts, ", ")+")")
+	}
+	return names
+}
+
+// registeredFormats returns the formats in the order of sniffing, the last registered first
+func registeredFormats() []fileFormat {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	list := make([]fileFormat, len(formats))
+	for i, format := range formats {
+		list[len(formats)-1-i] = format
+	}
+	return list
+}
+
+func lookupFormat(name string) (fileFormat, bool) {
+	for _, format := range registeredFormats() {
+		if format.name == name {
+			return format, true
+		}
+	}
+	for _, format := range registeredFormats() {
+		for _, alias := range format.aliases {
+			if alias == name {
+				return format, true
+			}
+		}
+	}
+	return fileFormat{}, false
+}
+
+// formatOf returns the format of the file by its extension
+func formatOf(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, format := range registeredFormats() {
+		for _, formatExt := range format.exts {
+			if strings.ToLower(formatExt) == ext {
+				return format.name
+			}
+		}
+	}
+	return ""
+}
+
+// formatName returns the format by its name, alias or extension
+func formatName(name string) string {
+	name = strings.ToLower(strings.TrimPrefix(name, "."))
+	if format, ok := lookupFormat(name); ok {
+		return format.name
+	}
+	return formatOf("." + name)
+}
@@ -0,0 +1,82 @@
+package easyconfig
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegisterFormat(t *testing.T) {
+	decodeKV := func(data []byte, structPtr interface{}) error {
+		if !bytes.HasPrefix(data, []byte("%kv\n")) {
+			return ErrUnknownFileType
+		}
+		mp := map[string]string{}
+		for _, line := range strings.Split(string(data), "\n") {
+			if p := strings.Fields(line); len(p) == 2 {
+				mp[p[0]] = p[1]
+			}
+		}
+		return map2struct("dir", "", mp, structPtr)
+	}
+	sniffKV := func(data []byte) bool {
+		return bytes.HasPrefix(data, []byte("%kv\n"))
+	}
+	formatsMu.RLock()
+	registered := append([]fileFormat(nil), formats...)
+	formatsMu.RUnlock()
+	t.Cleanup(func() {
+		formatsMu.Lock()
+		formats = registered
+		formatsMu.Unlock()
+	})
+	RegisterFormat("kv", []string{".kv"}, decodeKV, sniffKV)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"10-config.kv":  "%kv\npostgres-user postgres\n",
+		"20-config.txt": "%kv\npostgres-host localhost\n",
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("FileSource.Load", func(t *testing.T) {
+		config := new(Config)
+		if err := (FileSource{Path: filepath.Join(dir, "10-config.kv")}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "postgres" {
+			t.Errorf("PostgresUser = %q, want %q", config.PostgresUser, "postgres")
+		}
+		if err := (FileSource{Path: filepath.Join(dir, "20-config.txt")}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresHost != "localhost" {
+			t.Errorf("PostgresHost = %q, want %q", config.PostgresHost, "localhost")
+		}
+	})
+
+	t.Run("ConfDirSource.Load", func(t *testing.T) {
+		config := new(Config)
+		if err := (ConfDirSource{Path: dir}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "postgres" || config.PostgresHost != "" {
+			t.Errorf("Config = %+v, want %s", config, "only .kv file loaded")
+		}
+	})
+
+	t.Run("Formats", func(t *testing.T) {
+		formats := strings.Join(Formats(), ", ")
+		for _, format := range []string{"kv (.kv)", "yaml (.yaml, .yml)", "json (.json)", "edn (.edn)"} {
+			if !strings.Contains(formats, format) {
+				t.Errorf("Formats = %s, want %s", formats, format)
+			}
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package easyconfig
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FSSource loads the file-backed Source (FileSource, JSONSource, YAMLSource, TOMLSource,
+// EDNSource, EnvFileSource, DirSource or another single file source) from the file system,
+// e.g. embed.FS, a zip archive (zip.Reader) or fstest.MapFS. Paths are slash-separated paths
+// in FS, included files are read from FS too. SignedSource and SOPSSource are not supported.
+//
+//	//go:embed defaults.yaml
+//	var defaults embed.FS
+//
+//	easyconfig.FSSource{FS: defaults, Source: easyconfig.YAMLSource{Path: "defaults.yaml"}}
+type FSSource struct {
+	FS     fs.FS
+	Source Source
+}
+
+// Load configuration of the source from the file system
+func (s FSSource) Load(structPtr interface{}) error {
+	switch src := s.Source.(type) {
+	case FileSource:
+		return src.load(s.FS, structPtr)
+	case *FileSource:
+		return src.load(s.FS, structPtr)
+	case DirSource:
+		return src.load(s.FS, structPtr)
+	case *DirSource:
+		return src.load(s.FS, structPtr)
+	case SignedSource, *SignedSource, SOPSSource, *SOPSSource:
+		return ErrNotFileSource
+	}
+	src, ok := s.Source.(fileSource)
+	if !ok {
+		return ErrNotFileSource
+	}
+	data, err := readFileFS(s.FS, src.filePath())
+	if err != nil {
+		return err
+	}
+	return src.decode(s.FS, data, structPtr)
+}
+
+// verifiedFS reads files of FS (the OS file system when nil) accepted by verify,
+// so files included by a signed file are verified too
+type verifiedFS struct {
+	FS     fs.FS
+	verify func(path string, data []byte) error
+}
+
+type verifiedFile struct {
+	fs.File
+	reader *bytes.Reader
+}
+
+func (v verifiedFS) Open(name string) (fs.File, error) {
+	var file fs.File
+	var err error
+	if v.FS == nil {
+		file, err = os.Open(name)
+	} else {
+		file, err = v.FS.Open(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(file)
+	if err == nil {
+		err = v.verify(name, data)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return verifiedFile{File: file, reader: bytes.NewReader(data)}, nil
+}
+
+func (f verifiedFile) Read(p []byte) (int, error) {
+	return f.reader.Read(p)
+}
+
+// baseFS returns the file system read by fsys, nil is the OS file system
+func baseFS(fsys fs.FS) fs.FS {
+	if v, ok := fsys.(verifiedFS); ok {
+		return v.FS
+	}
+	return fsys
+}
+
+// fsPath converts the file path to the path in fs.FS: slash-separated, without leading "./" and "/"
+func fsPath(name string) string {
+	name = strings.TrimPrefix(path.Clean(filepath.ToSlash(name)), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// readFileFS reads the configuration file from fsys (the OS file system when nil),
+// a file encrypted as a whole (ENC[...]) is decrypted
+func readFileFS(fsys fs.FS, path string) ([]byte, error) {
+	data, err := readRawFS(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	return decryptFile(path, data)
+}
+
+func readRawFS(fsys fs.FS, path string) ([]byte, error) {
+	if v, ok := fsys.(verifiedFS); ok {
+		data, err := readRawFS(v.FS, path)
+		if err != nil {
+			return nil, err
+		}
+		return data, v.verify(path, data)
+	}
+	if fsys == nil {
+		return readRawFile(path)
+	}
+	return fs.ReadFile(fsys, fsPath(path))
+}
+
+func statFS(fsys fs.FS, path string) (fs.FileInfo, error) {
+	if fsys = baseFS(fsys); fsys == nil {
+		return os.Stat(path)
+	}
+	return fs.Stat(fsys, fsPath(path))
+}
+
+func globFS(fsys fs.FS, pattern string) ([]string, error) {
+	if fsys = baseFS(fsys); fsys == nil {
+		return filepath.Glob(pattern)
+	}
+	return fs.Glob(fsys, fsPath(pattern))
+}
+
+// readDirInfo returns the directory entries sorted by name
+func readDirInfo(fsys fs.FS, path string) ([]fs.FileInfo, error) {
+	if fsys = baseFS(fsys); fsys == nil {
+		return ioutil.ReadDir(path)
+	}
+	entries, err := fs.ReadDir(fsys, fsPath(path))
+	if err != nil {
+		return nil, err
+	}
+	files := make([]fs.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, info)
+	}
+	return files, nil
+}
@@ -0,0 +1,79 @@
+package easyconfig
+
+import (
+	"embed"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+//go:embed tests/config.json tests/config.env
+var testFS embed.FS
+
+func TestFSSourceLoader(t *testing.T) {
+	fsys := fstest.MapFS{
+		"etc/config.yaml":           {Data: []byte("postgresUser: postgres\ninclude: conf.d/*.yaml\n")},
+		"etc/conf.d/host.yaml":      {Data: []byte("postgresHost: localhost\n")},
+		"etc/app.toml":              {Data: []byte("postgresPort = \"port\"\n")},
+		"secrets/postgres-user":     {Data: []byte("admin")},
+		"secrets/postgres-password": {Data: []byte("password")},
+	}
+
+	t.Run("embed.FS", func(t *testing.T) {
+		config := new(Config)
+		if err := (FSSource{FS: testFS, Source: JSONSource{"tests/config.json"}}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "postgres" || config.PostgresPort != 5432 {
+			t.Errorf("Config = %+v, want %s", config, "postgres 5432")
+		}
+
+		config = new(Config)
+		if err := (FSSource{FS: testFS, Source: EnvFileSource{"APP", "./tests/config.env"}}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresDBName != "db-name" {
+			t.Errorf("PostgresDBName = %s, want %s", config.PostgresDBName, "db-name")
+		}
+	})
+
+	t.Run("fstest.MapFS", func(t *testing.T) {
+		config := new(Config)
+		if err := (FSSource{FS: fsys, Source: YAMLSource{"etc/config.yaml"}}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "postgres" || config.PostgresHost != "localhost" {
+			t.Errorf("Config = %+v, want %s", config, "postgres localhost")
+		}
+
+		if err := (FSSource{FS: fsys, Source: FileSource{Path: "/secrets"}}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "admin" || config.PostgresPassword != "password" {
+			t.Errorf("Config = %+v, want %s", config, "admin password")
+		}
+
+		for _, source := range []Source{DirSource{"secrets"}, &DirSource{"secrets"}, &FileSource{Path: "etc/config.yaml"}} {
+			if err := (FSSource{FS: fsys, Source: source}).Load(new(Config)); err != nil {
+				t.Errorf("%T: Error = %s, want %s", source, err.Error(), "nil")
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		err := (FSSource{FS: fsys, Source: FileSource{Path: "etc/app.toml"}}).Load(new(Config))
+		var fileErr *FileError
+		if !errors.As(err, &fileErr) || fileErr.Path != "etc/app.toml" || fileErr.Line != 1 {
+			t.Errorf("Error = %v, want %s", err, "etc/app.toml:1")
+		}
+		if err := (FSSource{FS: fsys, Source: YAMLSource{"etc/missing.yaml"}}).Load(new(Config)); err == nil || !strings.Contains(err.Error(), "etc/missing.yaml") {
+			t.Errorf("Error = %v, want not exist error", err)
+		}
+		for _, source := range []Source{EnvSource{"APP"}, SOPSSource{Path: "etc/config.yaml"}, SignedSource{Source: YAMLSource{"etc/config.yaml"}}} {
+			if err := (FSSource{FS: fsys, Source: source}).Load(new(Config)); !errors.Is(err, ErrNotFileSource) {
+				t.Errorf("%T: Error = %v, want %s", source, err, ErrNotFileSource)
+			}
+		}
+	})
+}
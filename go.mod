@@ -3,10 +3,14 @@ module github.com/night-codes/easyconfig
 go 1.16
 
 require (
+	filippo.io/age v1.0.0
 	github.com/BurntSushi/toml v1.2.0
 	github.com/fatih/color v1.13.0
+	github.com/hashicorp/hcl/v2 v2.11.1
 	github.com/iancoleman/strcase v0.2.0
 	github.com/joho/godotenv v1.4.0
-	gopkg.in/yaml.v2 v2.4.0
-	olympos.io/encoding/edn v0.0.0-20201019073823-d3554ca0b0a3 // indirect
+	github.com/zclconf/go-cty v1.8.0
+	golang.org/x/crypto v0.0.0-20210817164053-32db794688a5
+	gopkg.in/yaml.v3 v3.0.1
+	olympos.io/encoding/edn v0.0.0-20201019073823-d3554ca0b0a3
 )
@@ -0,0 +1,220 @@
+package easyconfig
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// HCLSource loads configuration from the given .hcl file (HCL2 native syntax). Attributes
+// and blocks match the hcl tag (gohcl style "name,attr", "name,block", "name,label") or the field
+// name ignoring case, "_" and "-". Blocks are decoded into nested structs, repeated blocks into
+// slices of structs, block labels into fields tagged ",label". Environment variables listed
+// in Env are available in expressions as env.NAME, no others are exposed.
+// Errors are hcl.Diagnostics with file, line and column.
+type HCLSource struct {
+	Path string
+	Env  []string
+}
+
+func init() {
+	registerFormat(fileFormat{
+		name:    "hcl",
+		exts:    []string{".hcl"},
+		decoder: func(data []byte, structPtr interface{}) error { return decodeHCL("", data, nil, structPtr) },
+		fileDecoder: func(path string, data []byte, structPtr interface{}) error {
+			return decodeHCL(path, data, nil, structPtr)
+		},
+	})
+}
+
+// Load HCL configuration file
+func (s HCLSource) Load(structPtr interface{}) error {
+	data, err := readFile(s.Path)
+	if err != nil {
+		return err
+	}
+	return s.decode(nil, data, structPtr)
+}
+
+func (s HCLSource) filePath() string { return s.Path }
+
+func (s HCLSource) decode(fsys fs.FS, data []byte, structPtr interface{}) error {
+	scratch := snapshot(structPtr)
+	if err := decodeHCL(s.Path, data, s.Env, scratch.Addr().Interface()); err != nil {
+		return fileError(fsys, s.Path, data, structPtr, err)
+	}
+	reflect.ValueOf(structPtr).Elem().Set(scratch)
+	return nil
+}
+
+// decodeHCL decodes HCL with the env variables listed in envNames
+func decodeHCL(path string, data []byte, envNames []string, structPtr interface{}) error {
+	file, diags := hclsyntax.ParseConfig(data, path, hcl.Pos{Line: 1, Column: 1, Byte: 0})
+	if diags.HasErrors() {
+		return diags
+	}
+	before := snapshot(structPtr)
+	if diags := decodeHCLBody(file.Body.(*hclsyntax.Body), hclContext(envNames), reflect.ValueOf(structPtr).Elem()); diags.HasErrors() {
+		return diags
+	}
+	return decodeValues(before, structPtr, nil)
+}
+
+func hclContext(envNames []string) *hcl.EvalContext {
+	if len(envNames) == 0 {
+		return nil
+	}
+	env := map[string]cty.Value{}
+	for _, name := range envNames {
+		if value, ok := os.LookupEnv(name); ok && hclsyntax.ValidIdentifier(name) {
+			env[name] = cty.StringVal(value)
+		}
+	}
+	return &hcl.EvalContext{Variables: map[string]cty.Value{"env": cty.ObjectVal(env)}}
+}
+
+func decodeHCLBody(body *hclsyntax.Body, ctx *hcl.EvalContext, structElem reflect.Value) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	for name, attr := range body.Attributes {
+		field, _, ok := structField(structElem, "hcl", name)
+		if !ok {
+			continue
+		}
+		value, valueDiags := attr.Expr.Value(ctx)
+		if diags = append(diags, valueDiags...); valueDiags.HasErrors() {
+			continue
+		}
+		if err := setCtyValue(field, value); err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unsuitable value",
+				Detail:   fmt.Sprintf("%s: %s", name, err),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+	}
+
+	reset := map[string]bool{}
+	for _, block := range body.Blocks {
+		field, _, ok := structField(structElem, "hcl", block.Type)
+		if !ok {
+			continue
+		}
+		switch {
+		case field.Kind() == reflect.Slice:
+			// repeated blocks append, values of the previous source are replaced
+			if !reset[block.Type] {
+				reset[block.Type] = true
+				field.Set(reflect.Zero(field.Type()))
+			}
+			item := reflect.New(field.Type().Elem()).Elem()
+			diags = append(diags, decodeHCLBlock(block, ctx, item)...)
+			field.Set(reflect.Append(field, item))
+		default:
+			diags = append(diags, decodeHCLBlock(block, ctx, field)...)
+		}
+	}
+	return diags
+}
+
+func decodeHCLBlock(block *hclsyntax.Block, ctx *hcl.EvalContext, value reflect.Value) hcl.Diagnostics {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Unexpected block",
+			Detail:   fmt.Sprintf("%s is not a struct field", block.Type),
+			Subject:  block.TypeRange.Ptr(),
+		}}
+	}
+
+	labels := block.Labels
+	for i := 0; i < value.NumField() && len(labels) > 0; i++ {
+		tag := strings.SplitN(value.Type().Field(i).Tag.Get("hcl"), ",", 2)
+		if len(tag) == 2 && tag[1] == "label" && value.Field(i).Kind() == reflect.String {
+			value.Field(i).SetString(labels[0])
+			labels = labels[1:]
+		}
+	}
+	return decodeHCLBody(block.Body, ctx, value)
+}
+
+// setCtyValue sets the HCL value to the field
+func setCtyValue(field reflect.Value, value cty.Value) error {
+	if value.IsNull() {
+		return nil
+	}
+	if !value.IsKnown() {
+		return fmt.Errorf("value is unknown")
+	}
+	ty := value.Type()
+	switch {
+	case ty == cty.String:
+		setField(field.Addr().Interface(), value.AsString())
+	case ty == cty.Number:
+		setField(field.Addr().Interface(), value.AsBigFloat().Text('f', -1))
+	case ty == cty.Bool:
+		setField(field.Addr().Interface(), fmt.Sprint(value.True()))
+	case ty.IsListType() || ty.IsTupleType() || ty.IsSetType():
+		if field.Kind() != reflect.Slice {
+			return fmt.Errorf("list for %s field", field.Kind())
+		}
+		slice := reflect.MakeSlice(field.Type(), 0, value.LengthInt())
+		for it := value.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			item := reflect.New(field.Type().Elem()).Elem()
+			if err := setCtyValue(item, v); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, item)
+		}
+		field.Set(slice)
+	case ty.IsObjectType() || ty.IsMapType():
+		if field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.Struct {
+			if field.IsNil() {
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+			field = field.Elem()
+		}
+		switch field.Kind() {
+		case reflect.Struct:
+			for it := value.ElementIterator(); it.Next(); {
+				k, v := it.Element()
+				if f, _, ok := structField(field, "hcl", k.AsString()); ok {
+					if err := setCtyValue(f, v); err != nil {
+						return err
+					}
+				}
+			}
+		case reflect.Map:
+			if field.IsNil() {
+				field.Set(reflect.MakeMap(field.Type()))
+			}
+			for it := value.ElementIterator(); it.Next(); {
+				k, v := it.Element()
+				item := reflect.New(field.Type().Elem()).Elem()
+				if err := setCtyValue(item, v); err != nil {
+					return err
+				}
+				field.SetMapIndex(reflect.ValueOf(k.AsString()).Convert(field.Type().Key()), item)
+			}
+		default:
+			return fmt.Errorf("object for %s field", field.Kind())
+		}
+	default:
+		return fmt.Errorf("unsupported type %s", ty.FriendlyName())
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package easyconfig
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+type (
+	HCLConfig struct {
+		Name     string   `hcl:"name,attr"`
+		Home     string   `hcl:"home,optional"`
+		Tags     []string `hcl:"tags,optional"`
+		Postgres struct {
+			User    string
+			Host    string
+			Port    int
+			SSLMode string
+		} `hcl:"postgres,block"`
+		Listeners []struct {
+			Name string `hcl:"name,label"`
+			Port int    `hcl:"port"`
+			TLS  bool   `hcl:"tls,optional"`
+		} `hcl:"listener,block"`
+	}
+)
+
+func TestHCLSourceLoader(t *testing.T) {
+	os.Setenv("APP_TEST_HCL_HOME", "/home/app")
+	defer os.Unsetenv("APP_TEST_HCL_HOME")
+
+	t.Run("HCLSource.Load", func(t *testing.T) {
+		config := new(HCLConfig)
+		if err := (HCLSource{Path: "tests/config.hcl", Env: []string{"APP_TEST_HCL_HOME"}}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.Name != "easyconfig" || config.Home != "/home/app" || strings.Join(config.Tags, ",") != "a1,a2" {
+			t.Errorf("Config = %+v, want %s", config, "easyconfig /home/app [a1 a2]")
+		}
+		if config.Postgres.User != "postgres" || config.Postgres.Port != 5432 || config.Postgres.SSLMode != "disable" {
+			t.Errorf("Postgres = %+v, want %s", config.Postgres, "postgres 5432 disable")
+		}
+		if len(config.Listeners) != 2 || config.Listeners[0].Name != "http" || config.Listeners[1].Port != 8443 || !config.Listeners[1].TLS {
+			t.Errorf("Listeners = %+v, want %s", config.Listeners, "http 8080, https 8443 tls")
+		}
+	})
+
+	t.Run("FileSource.Load", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.hcl")
+		if err := ioutil.WriteFile(path, []byte("postgres {\n  host = \"localhost\"\n}\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		config := new(HCLConfig)
+		if err := (FileSource{Path: path}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.Postgres.Host != "localhost" {
+			t.Errorf("Host = %s, want %s", config.Postgres.Host, "localhost")
+		}
+	})
+
+	t.Run("env", func(t *testing.T) {
+		for _, env := range [][]string{nil, {"APP_TEST_HCL_OTHER"}} {
+			config := new(HCLConfig)
+			if err := (HCLSource{Path: "tests/config.hcl", Env: env}).Load(config); err == nil {
+				t.Errorf("Env %v: Error = nil, want env.APP_TEST_HCL_HOME not available", env)
+			}
+			if config.Home != "" {
+				t.Errorf("Env %v: Home = %s, want %s", env, config.Home, "")
+			}
+		}
+		if err := (FileSource{Path: "tests/config.hcl"}).Load(new(HCLConfig)); err == nil {
+			t.Errorf("Error = nil, want env.APP_TEST_HCL_HOME not available")
+		}
+	})
+
+	t.Run("diagnostics", func(t *testing.T) {
+		err := (HCLSource{Path: "tests/config.hcl"}).decode(nil, []byte("name = \"easyconfig\"\nport = \n"), new(HCLConfig))
+		var diags hcl.Diagnostics
+		path, _ := filepath.Abs("tests/config.hcl")
+		if !errors.As(err, &diags) || diags[0].Subject.Start.Line != 2 || !strings.HasPrefix(err.Error(), path+":2:8: Invalid expression") {
+			t.Errorf("Error = %v, want diagnostics at %s:2:8", err, path)
+		}
+	})
+}
@@ -0,0 +1,259 @@
+package easyconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	yaml "gopkg.in/yaml.v3"
+	"olympos.io/encoding/edn"
+)
+
+// IncludeKeys are the top-level keys of JSON, YAML, TOML and EDN files with paths (or globs)
+// of files to include. Paths are relative to the including file, included files are merged
+// over it in lexical order. YAML values tagged !include are replaced with the included file.
+var IncludeKeys = []string{"include", "$import"}
+
+const ErrIncludeCycle strErr = "include cycle"
+
+// includeFile decodes the file and then the files it includes, stack holds the including files
+func includeFile(fsys fs.FS, stack []string, format, path string, data []byte, structPtr interface{}) error {
+	stack, err := pushInclude(stack, path)
+	if err != nil {
+		return err
+	}
+	if format == "" {
+		format = sniffFormat(data)
+	}
+	if format == "yaml" {
+		if data, err = expandYAMLIncludes(fsys, stack, path, data); err != nil {
+			return err
+		}
+	}
+	if err := decodeFormat(format, path, data, structPtr); err != nil {
+		return fileError(fsys, path, data, structPtr, err)
+	}
+
+	for _, pattern := range includePaths(format, data) {
+		paths, err := globPaths(fsys, filepath.Dir(path), pattern)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for _, includePath := range paths {
+			data, err := readFileFS(fsys, includePath)
+			if err != nil {
+				return err
+			}
+			if err := includeFile(fsys, stack, formatOf(includePath), includePath, data, structPtr); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func pushInclude(stack []string, path string) ([]string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range stack {
+		if p == abs {
+			return nil, fmt.Errorf("%s: %w", strings.Join(append(stack, abs), " -> "), ErrIncludeCycle)
+		}
+	}
+	return append(stack[:len(stack):len(stack)], abs), nil
+}
+
+// includePaths returns values of IncludeKeys of the file
+func includePaths(format string, data []byte) []string {
+	mp := map[string]interface{}{}
+	switch format {
+	case "json":
+		_ = json.Unmarshal(data, &mp)
+	case "json5":
+		if converted, _, err := json5ToJSON(data); err == nil {
+			_ = json.Unmarshal(converted, &mp)
+		}
+	case "yaml":
+		docs, _ := yamlDocuments(data)
+		for _, doc := range docs {
+			_ = doc.Decode(&mp)
+		}
+	case "toml":
+		_, _ = toml.Decode(string(data), &mp)
+	case "edn":
+		ednMap := map[interface{}]interface{}{}
+		_ = edn.Unmarshal(data, &ednMap)
+		for key, value := range ednMap {
+			mp[strings.TrimPrefix(fmt.Sprint(key), ":")] = value
+		}
+	}
+
+	paths := []string{}
+	for _, key := range IncludeKeys {
+		switch value := mp[key].(type) {
+		case string:
+			paths = append(paths, value)
+		case []interface{}:
+			for _, v := range value {
+				if path, ok := v.(string); ok {
+					paths = append(paths, path)
+				}
+			}
+		}
+	}
+	return paths
+}
+
+// globPaths returns files matching the pattern relative to dir in lexical order,
+// a pattern without wildcards must match an existing file
+func globPaths(fsys fs.FS, dir, pattern string) ([]string, error) {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(dir, pattern)
+	}
+	if !strings.ContainsAny(pattern, "*?[") {
+		if _, err := statFS(fsys, pattern); err != nil {
+			return nil, err
+		}
+		return []string{pattern}, nil
+	}
+	paths, err := globFS(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// expandYAMLIncludes replaces values tagged !include in all documents with the content of included files
+func expandYAMLIncludes(fsys fs.FS, stack []string, path string, data []byte) ([]byte, error) {
+	docs, err := yamlDocuments(data)
+	if err != nil {
+		return data, nil
+	}
+	found := false
+	for _, doc := range docs {
+		ok, err := replaceIncludeTags(fsys, stack, filepath.Dir(path), doc)
+		if err != nil {
+			return nil, err
+		}
+		found = found || ok
+	}
+	if !found {
+		return data, nil
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	for _, doc := range docs {
+		if err := encoder.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func replaceIncludeTags(fsys fs.FS, stack []string, dir string, node *yaml.Node) (bool, error) {
+	if node.Tag != "!include" {
+		found := false
+		for _, child := range node.Content {
+			ok, err := replaceIncludeTags(fsys, stack, dir, child)
+			if err != nil {
+				return false, err
+			}
+			found = found || ok
+		}
+		return found, nil
+	}
+
+	paths, err := globPaths(fsys, dir, node.Value)
+	if err != nil {
+		return false, err
+	}
+	var merged *yaml.Node
+	for _, includePath := range paths {
+		content, err := includeNode(fsys, stack, includePath)
+		if err != nil {
+			return false, err
+		}
+		merged = mergeNodes(merged, content)
+	}
+	if merged == nil {
+		merged = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+	*node = *merged
+	return true, nil
+}
+
+// includeNode reads the YAML, JSON or TOML file as a YAML node
+func includeNode(fsys fs.FS, stack []string, path string) (*yaml.Node, error) {
+	stack, err := pushInclude(stack, path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readFileFS(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := new(yaml.Node)
+	switch formatOf(path) {
+	case "yaml", "json":
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	case "toml":
+		mp := map[string]interface{}{}
+		if _, err := toml.Decode(string(data), &mp); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if err := doc.Encode(mp); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%s: %w", path, ErrUnknownFileType)
+	}
+	if doc.Kind == yaml.DocumentNode {
+		if len(doc.Content) == 0 {
+			return nil, nil
+		}
+		doc = doc.Content[0]
+	}
+	if _, err := replaceIncludeTags(fsys, stack, filepath.Dir(path), doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// mergeNodes merges mapping src into dst, other nodes are replaced
+func mergeNodes(dst, src *yaml.Node) *yaml.Node {
+	if dst == nil || src == nil || dst.Kind != yaml.MappingNode || src.Kind != yaml.MappingNode {
+		if src == nil {
+			return dst
+		}
+		return src
+	}
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		replaced := false
+		for j := 0; j+1 < len(dst.Content); j += 2 {
+			if dst.Content[j].Value == key.Value {
+				dst.Content[j+1] = mergeNodes(dst.Content[j+1], value)
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			dst.Content = append(dst.Content, key, value)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,102 @@
+package easyconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type (
+	IncludeConfig struct {
+		PostgresUser string `json:"postgresUser" yaml:"postgresUser" toml:"postgresUser"`
+		PostgresHost string `json:"postgresHost" yaml:"postgresHost" toml:"postgresHost"`
+		PostgresPort int    `json:"postgresPort" yaml:"postgresPort" toml:"postgresPort"`
+		Cache        struct {
+			Host string `json:"host" yaml:"host" toml:"host"`
+			Size int    `json:"size" yaml:"size" toml:"size"`
+		} `json:"cache" yaml:"cache" toml:"cache"`
+	}
+)
+
+func TestIncludes(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	write("conf.d/10-host.yaml", "postgresHost: db\npostgresPort: 5433\n")
+	write("conf.d/20-port.yaml", "postgresPort: 6432\n")
+	write("conf.d/ignored.json", `{"postgresPort": 1}`)
+	write("cache/base.yaml", "host: cache\nsize: 1\n")
+	write("cache/size.toml", "size = 64\n")
+	write("user.json", `{"postgresUser": "postgres"}`)
+
+	t.Run("YAMLSource.Load", func(t *testing.T) {
+		path := write("config.yaml", "include: conf.d/*.yaml\npostgresHost: localhost\ncache: !include cache/*\n")
+		config := new(IncludeConfig)
+		if err := (YAMLSource{path}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresHost != "db" {
+			t.Errorf("PostgresHost = %s, want %s", config.PostgresHost, "db")
+		}
+		if config.PostgresPort != 6432 {
+			t.Errorf("PostgresPort = %d, want %d", config.PostgresPort, 6432)
+		}
+		if fmt.Sprintf("%+v", config.Cache) != "{Host:cache Size:64}" {
+			t.Errorf("Cache = %+v, want %s", config.Cache, "{Host:cache Size:64}")
+		}
+	})
+
+	t.Run("JSONSource.Load", func(t *testing.T) {
+		path := write("config.json", `{"$import": ["user.json", "conf.d/10-host.yaml"], "postgresPort": 5432}`)
+		config := new(IncludeConfig)
+		if err := (JSONSource{path}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "postgres" || config.PostgresHost != "db" || config.PostgresPort != 5433 {
+			t.Errorf("Config = %+v, want %s", config, "postgres db 5433")
+		}
+	})
+
+	t.Run("TOMLSource.Load", func(t *testing.T) {
+		path := write("config.toml", "include = [\"user.json\"]\npostgresPort = 5432\n")
+		config := new(IncludeConfig)
+		if err := (TOMLSource{path}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "postgres" || config.PostgresPort != 5432 {
+			t.Errorf("Config = %+v, want %s", config, "postgres 5432")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		path := write("missing.yaml", "include: not-found.yaml\n")
+		if err := (YAMLSource{path}).Load(new(IncludeConfig)); !os.IsNotExist(errors.Unwrap(err)) {
+			t.Errorf("Error = %v, want not exist error", err)
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		write("a.yaml", "include: b.yaml\n")
+		write("b.yaml", "include: a.yaml\n")
+		if err := (FileSource{Path: filepath.Join(dir, "a.yaml")}).Load(new(IncludeConfig)); !errors.Is(err, ErrIncludeCycle) {
+			t.Errorf("Error = %v, want %s", err, ErrIncludeCycle)
+		}
+
+		write("c.yaml", "cache: !include d.yaml\n")
+		write("d.yaml", "host: !include c.yaml\n")
+		if err := (YAMLSource{filepath.Join(dir, "c.yaml")}).Load(new(IncludeConfig)); !errors.Is(err, ErrIncludeCycle) {
+			t.Errorf("Error = %v, want %s", err, ErrIncludeCycle)
+		}
+	})
+}
@@ -0,0 +1,244 @@
+package easyconfig
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// INISource loads configuration from the given .ini or .cfg file. Sections ([postgres],
+// [postgres.replica]) map to nested structs, keys to fields by the ini tag or by the field name
+// ignoring case, "_" and "-". Values may be quoted, lines ending with \ are continued,
+// repeated keys are collected into slice fields. Comments start with ; or #.
+type INISource struct {
+	Path string
+}
+
+const ErrINISyntax strErr = "invalid ini syntax"
+
+type iniEntry struct {
+	section, key, value string
+}
+
+func init() {
+	RegisterFormat("ini", []string{".ini", ".cfg"}, decodeINI, sniffINI)
+}
+
+// Load INI configuration file
+func (s INISource) Load(structPtr interface{}) error {
+	data, err := readFile(s.Path)
+	if err != nil {
+		return err
+	}
+	return s.decode(nil, data, structPtr)
+}
+
+func (s INISource) filePath() string { return s.Path }
+
+func (s INISource) decode(fsys fs.FS, data []byte, structPtr interface{}) error {
+	return includeFile(fsys, nil, "ini", s.Path, data, structPtr)
+}
+
+// sniffINI detects a file starting with a [section] that is not valid TOML
+func sniffINI(data []byte) bool {
+	if !tomlRe.MatchString(firstLine(data)) {
+		return false
+	}
+	var v map[string]interface{}
+	if _, err := toml.Decode(string(data), &v); err == nil {
+		return false
+	}
+	_, err := parseINI(data)
+	return err == nil
+}
+
+func decodeINI(data []byte, structPtr interface{}) error {
+	entries, err := parseINI(data)
+	if err != nil {
+		return err
+	}
+	before := snapshot(structPtr)
+	structElem := reflect.ValueOf(structPtr).Elem()
+	reset := map[string]bool{}
+	for _, entry := range entries {
+		field, separator, ok := iniField(structElem, entry.section, entry.key)
+		if !ok {
+			continue
+		}
+		if field.Kind() == reflect.Slice {
+			// repeated keys append, values of the previous source are replaced
+			id := entry.section + "\x00" + entry.key
+			if !reset[id] {
+				reset[id] = true
+				field.Set(reflect.Zero(field.Type()))
+			}
+			if separator == "" {
+				separator = "\x00"
+			}
+			setSlice(field.Addr().Interface(), entry.value, separator)
+			continue
+		}
+		setField(field.Addr().Interface(), entry.value)
+	}
+	return decodeValues(before, structPtr, nil)
+}
+
+// parseINI reads key=value (or key: value) entries of the sections
+func parseINI(data []byte) ([]iniEntry, error) {
+	entries := []iniEntry{}
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	scanner.Buffer(make([]byte, 64*1024), maxFileSize)
+	for n := 1; scanner.Scan(); n++ {
+		start := n
+		line := strings.TrimSpace(scanner.Text())
+		for strings.HasSuffix(line, "\\") && !strings.HasSuffix(line, "\\\\") {
+			line = strings.TrimSuffix(line, "\\")
+			if !scanner.Scan() {
+				break
+			}
+			n++
+			line += strings.TrimSpace(scanner.Text())
+		}
+
+		switch {
+		case line == "" || line[0] == ';' || line[0] == '#':
+		case line[0] == '[':
+			end := strings.Index(line, "]")
+			if end < 0 || strings.TrimSpace(stripINIComment(line[end+1:])) != "" {
+				return nil, fmt.Errorf("line %d: %w: %s", start, ErrINISyntax, line)
+			}
+			section = strings.TrimSpace(line[1:end])
+		default:
+			i := strings.IndexAny(line, "=:")
+			if i <= 0 {
+				return nil, fmt.Errorf("line %d: %w: %s", start, ErrINISyntax, line)
+			}
+			value, err := iniValue(strings.TrimSpace(line[i+1:]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w: %s", start, ErrINISyntax, line)
+			}
+			entries = append(entries, iniEntry{section: section, key: strings.TrimSpace(line[:i]), value: value})
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// iniValue unquotes the value or strips the inline comment
+func iniValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		end := closingQuote(value)
+		if end < 0 || strings.TrimSpace(stripINIComment(value[end+1:])) != "" {
+			return "", ErrINISyntax
+		}
+		return strconv.Unquote(value[:end+1])
+	case strings.HasPrefix(value, "'"):
+		end := strings.Index(value[1:], "'") + 1
+		if end <= 0 || strings.TrimSpace(stripINIComment(value[end+1:])) != "" {
+			return "", ErrINISyntax
+		}
+		return value[1:end], nil
+	}
+	return strings.TrimSpace(stripINIComment(value)), nil
+}
+
+func closingQuote(value string) int {
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// stripINIComment removes " ;comment" and " #comment" from the value
+func stripINIComment(value string) string {
+	if strings.HasPrefix(value, ";") || strings.HasPrefix(value, "#") {
+		return ""
+	}
+	for i := 1; i < len(value); i++ {
+		if (value[i] == ';' || value[i] == '#') && (value[i-1] == ' ' || value[i-1] == '\t') {
+			return value[:i]
+		}
+	}
+	return value
+}
+
+// iniField returns the field of the section.key, nested sections are separated by dots
+func iniField(structElem reflect.Value, section, key string) (reflect.Value, string, bool) {
+	path := []string{}
+	if section != "" {
+		path = strings.Split(section, ".")
+	}
+	return nestedField(structElem, "ini", append(path, key))
+}
+
+// nestedField returns the field by the path of names, nested struct pointers are allocated.
+// Names match the tag or the field name ignoring case, "_" and "-".
+func nestedField(structElem reflect.Value, tag string, path []string) (reflect.Value, string, bool) {
+	for _, name := range path[:len(path)-1] {
+		field, _, ok := structField(structElem, tag, name)
+		if !ok {
+			return reflect.Value{}, "", false
+		}
+		if field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.Struct {
+			if field.IsNil() {
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+			field = field.Elem()
+		}
+		if field.Kind() != reflect.Struct {
+			return reflect.Value{}, "", false
+		}
+		structElem = field
+	}
+	return structField(structElem, tag, path[len(path)-1])
+}
+
+func structField(structElem reflect.Value, tag, name string) (reflect.Value, string, bool) {
+	i, separator, ok := fieldIndex(structElem.Type(), tag, name)
+	if !ok {
+		return reflect.Value{}, "", false
+	}
+	return structElem.Field(i), separator, true
+}
+
+// fieldIndex returns the index of the struct field matching the tag or the field name ignoring case, "_" and "-"
+func fieldIndex(structType reflect.Type, tag, name string) (int, string, bool) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tagVal := strings.TrimSpace(field.Tag.Get(tag))
+		if tagVal == "-" {
+			continue
+		}
+		fieldName, separator := field.Name, ""
+		if strings.Contains(tagVal, ",") {
+			p := strings.SplitN(tagVal, ",", 2)
+			tagVal, separator = p[0], p[1]
+		}
+		if tagVal != "" {
+			fieldName = tagVal
+		}
+		if tagVal == name || normalizeName(fieldName) == normalizeName(name) {
+			return i, separator, true
+		}
+	}
+	return 0, "", false
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "", " ", "", ".", "").Replace(name))
+}
@@ -0,0 +1,77 @@
+package easyconfig
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type (
+	INIConfig struct {
+		Name     string
+		Mode     string `ini:"mode"`
+		Postgres struct {
+			User     string
+			Password string
+			Host     string
+			Port     int
+			Hosts    []string
+			DSN      string
+			Replica  *struct {
+				Host string `ini:"host"`
+			}
+		}
+	}
+)
+
+func TestINISourceLoader(t *testing.T) {
+	t.Run("INISource.Load", func(t *testing.T) {
+		config := new(INIConfig)
+		if err := (INISource{"tests/config.ini"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.Name != `easy "config"` {
+			t.Errorf("Name = %s, want %s", config.Name, `easy "config"`)
+		}
+		if config.Mode != "production" {
+			t.Errorf("Mode = %s, want %s", config.Mode, "production")
+		}
+		if config.Postgres.User != "postgres" || config.Postgres.Host != "localhost" || config.Postgres.Port != 5432 {
+			t.Errorf("Postgres = %+v, want %s", config.Postgres, "postgres localhost 5432")
+		}
+		if config.Postgres.Password != "pass;word" {
+			t.Errorf("Password = %s, want %s", config.Postgres.Password, "pass;word")
+		}
+		if fmt.Sprint(config.Postgres.Hosts) != "[db1 db2]" {
+			t.Errorf("Hosts = %v, want %s", config.Postgres.Hosts, "[db1 db2]")
+		}
+		if config.Postgres.DSN != "host=localhost dbname=db-name" {
+			t.Errorf("DSN = %s, want %s", config.Postgres.DSN, "host=localhost dbname=db-name")
+		}
+		if config.Postgres.Replica == nil || config.Postgres.Replica.Host != "replica" {
+			t.Errorf("Replica = %+v, want %s", config.Postgres.Replica, "replica")
+		}
+	})
+
+	t.Run("FileSource.Load", func(t *testing.T) {
+		config := new(INIConfig)
+		if err := (FileSource{Path: "tests/config.ini"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.Postgres.User != "postgres" {
+			t.Errorf("User = %s, want %s", config.Postgres.User, "postgres")
+		}
+		if sniffFormat([]byte("[postgres]\nuser = postgres\n")) != "ini" {
+			t.Errorf("sniffFormat = %s, want %s", sniffFormat([]byte("[postgres]\nuser = postgres\n")), "ini")
+		}
+		if sniffFormat([]byte("[postgres]\nuser = \"postgres\"\n")) != "toml" {
+			t.Errorf("sniffFormat = %s, want %s", sniffFormat([]byte("[postgres]\nuser = \"postgres\"\n")), "toml")
+		}
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		if err := decodeINI([]byte("[postgres]\nuser\n"), new(INIConfig)); !errors.Is(err, ErrINISyntax) {
+			t.Errorf("Error = %v, want %s", err, ErrINISyntax)
+		}
+	})
+}
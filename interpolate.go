@@ -0,0 +1,337 @@
+package easyconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/iancoleman/strcase"
+)
+
+const (
+	ErrInterpolationCycle strErr = "interpolation cycle"
+	ErrRequiredVariable   strErr = "required variable is not set"
+	ErrInvalidReference   strErr = "invalid ${...} reference"
+	ErrSecretReference    strErr = "secret fields can not be referenced"
+)
+
+type (
+	// interpolator expands ${VAR}, ${VAR:-default} and ${VAR:?error} in string values set by a source.
+	// VAR is a field of the config (Go name, lowerCamel name or json/yaml/toml/edn tag,
+	// nested fields joined with ".") or an environment variable. $${ is left as literal ${.
+	// Secret fields and decrypted values are not expanded, secret fields can not be referenced.
+	interpolator struct {
+		fields  []*refField
+		names   map[string]*refField
+		pending []string
+	}
+
+	refField struct {
+		path    string
+		value   reflect.Value
+		changed bool
+		skip    bool
+		secret  bool
+		state   int
+	}
+)
+
+// Interpolation enables ${...} expansion in values of file sources and DirSource. Set it to false
+// before loading to keep values with a literal ${ (passwords, templates) as they are.
+var Interpolation = true
+
+const (
+	refPending = iota
+	refResolving
+	refDone
+)
+
+// interpolateStruct expands references in the fields changed since the before snapshot,
+// fields of the skip paths are left as is
+func interpolateStruct(before reflect.Value, structPtr interface{}, skip map[string]bool) error {
+	if !Interpolation {
+		return nil
+	}
+	in := &interpolator{names: map[string]*refField{}}
+	in.collect(before, reflect.ValueOf(structPtr).Elem(), "", []string{""}, skip)
+	for _, field := range in.fields {
+		if err := in.resolve(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshot returns a copy of the struct that does not share slices, maps and pointers with it
+func snapshot(structPtr interface{}) reflect.Value {
+	value := reflect.ValueOf(structPtr).Elem()
+	cp := reflect.New(value.Type()).Elem()
+	deepCopy(cp, value)
+	return cp
+}
+
+func deepCopy(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Struct:
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if src.Type().Field(i).PkgPath == "" {
+				deepCopy(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		for _, key := range src.MapKeys() {
+			value := reflect.New(src.Type().Elem()).Elem()
+			deepCopy(value, src.MapIndex(key))
+			dst.SetMapIndex(key, value)
+		}
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.New(src.Type().Elem()))
+		deepCopy(dst.Elem(), src.Elem())
+	default:
+		dst.Set(src)
+	}
+}
+
+func (in *interpolator) collect(before, after reflect.Value, path string, prefixes []string, skip map[string]bool) {
+	for i := 0; i < after.NumField(); i++ {
+		field := after.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+		names := make([]string, 0, len(prefixes)*6)
+		for _, prefix := range prefixes {
+			for _, name := range refNames(field) {
+				names = append(names, prefix+name)
+			}
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			nested := make([]string, len(names))
+			for j, name := range names {
+				nested[j] = name + "."
+			}
+			in.collect(before.Field(i), after.Field(i), fieldPath, nested, skip)
+			continue
+		}
+
+		ref := &refField{
+			path:    fieldPath,
+			value:   after.Field(i),
+			changed: !reflect.DeepEqual(before.Field(i).Interface(), after.Field(i).Interface()),
+			skip:    isSecret(field) || skip[fieldPath],
+			secret:  isSecret(field),
+		}
+		in.fields = append(in.fields, ref)
+		for _, name := range names {
+			if _, ok := in.names[name]; !ok {
+				in.names[name] = ref
+			}
+		}
+	}
+}
+
+// refNames returns the names a field can be referenced by
+func refNames(field reflect.StructField) []string {
+	names := []string{field.Name, strcase.ToLowerCamel(field.Name)}
+	for _, tag := range []string{"json", "yaml", "toml", "edn", "xml"} {
+		name := strings.TrimSpace(strings.SplitN(field.Tag.Get(tag), ",", 2)[0])
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// resolve expands references in the field if it was set by the current source
+func (in *interpolator) resolve(field *refField) error {
+	switch {
+	case !field.changed || field.skip || field.state == refDone:
+		return nil
+	case field.state == refResolving:
+		return fmt.Errorf("%s: %w", strings.Join(append(in.pending, field.path), " -> "), ErrInterpolationCycle)
+	}
+	field.state = refResolving
+	in.pending = append(in.pending, field.path)
+	defer func() { in.pending = in.pending[:len(in.pending)-1] }()
+
+	if err := in.expandValue(field.value); err != nil {
+		if len(in.pending) > 1 || errors.Is(err, ErrInterpolationCycle) {
+			return err
+		}
+		return fmt.Errorf("%s: %w", field.path, err)
+	}
+	field.state = refDone
+	return nil
+}
+
+func (in *interpolator) expandValue(value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.String:
+		if strings.Contains(value.String(), "$") {
+			expanded, err := in.expand(value.String())
+			if err != nil {
+				return err
+			}
+			value.SetString(expanded)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := in.expandValue(value.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Ptr:
+		if !value.IsNil() {
+			return in.expandValue(value.Elem())
+		}
+	}
+	return nil
+}
+
+func (in *interpolator) expand(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "$${"):
+			b.WriteString("${")
+			i += 2
+		case strings.HasPrefix(s[i:], "${"):
+			end := closingBrace(s, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("${%s: %w: missing }", refName(s[i+2:]), ErrInvalidReference)
+			}
+			value, err := in.reference(s[i+2 : end])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i = end
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// reference returns the value of NAME, NAME:-default or NAME:?error
+func (in *interpolator) reference(expr string) (string, error) {
+	name, op, arg := expr, "", ""
+	if i := strings.Index(expr, ":"); i >= 0 {
+		name, op, arg = expr[:i], expr[i:], ""
+		if len(op) >= 2 {
+			op, arg = expr[i:i+2], expr[i+2:]
+		}
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || (op != "" && op != ":-" && op != ":?") {
+		return "", fmt.Errorf("${%s}: %w", refName(name), ErrInvalidReference)
+	}
+
+	value, err := in.lookup(name)
+	if err != nil || value != "" {
+		return value, err
+	}
+	switch op {
+	case ":-":
+		return in.expand(arg)
+	case ":?":
+		if arg != "" {
+			return "", fmt.Errorf("%s: %w: %s", name, ErrRequiredVariable, arg)
+		}
+		return "", fmt.Errorf("%s: %w", name, ErrRequiredVariable)
+	}
+	return "", nil
+}
+
+// lookup returns the value of the config field or, when it is empty, the environment variable
+func (in *interpolator) lookup(name string) (string, error) {
+	if field, ok := in.names[name]; ok {
+		if field.secret {
+			return "", fmt.Errorf("${%s}: %w", name, ErrSecretReference)
+		}
+		if err := in.resolve(field); err != nil {
+			return "", err
+		}
+		if !field.value.IsZero() {
+			if field.value.Kind() == reflect.String {
+				return field.value.String(), nil
+			}
+			return fmt.Sprint(field.value.Interface()), nil
+		}
+	}
+	return os.Getenv(name), nil
+}
+
+// refName returns the leading name characters of the reference, so errors do not echo values
+func refName(expr string) string {
+	end := strings.IndexFunc(expr, func(r rune) bool {
+		return !(r == '_' || r == '.' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	if end < 0 {
+		return expr
+	}
+	return expr[:end]
+}
+
+// changedPaths returns paths of the fields changed since the before snapshot (Go names joined with ".")
+func changedPaths(before, after reflect.Value, path string) map[string]bool {
+	paths := map[string]bool{}
+	for i := 0; i < after.NumField(); i++ {
+		field := after.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+		if field.Type.Kind() == reflect.Struct {
+			for nested := range changedPaths(before.Field(i), after.Field(i), fieldPath) {
+				paths[nested] = true
+			}
+		} else if !reflect.DeepEqual(before.Field(i).Interface(), after.Field(i).Interface()) {
+			paths[fieldPath] = true
+		}
+	}
+	return paths
+}
+
+// closingBrace returns the index of the brace closing ${ that starts before from, nested ${...} are skipped
+func closingBrace(s string, from int) int {
+	depth := 1
+	for i := from; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
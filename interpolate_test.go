@@ -0,0 +1,185 @@
+package easyconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type (
+	InterpolationConfig struct {
+		PostgresHost string `json:"postgresHost" yaml:"postgresHost"`
+		PostgresPort int    `json:"postgresPort" yaml:"postgresPort"`
+		PostgresURL  string `json:"postgresURL" yaml:"postgresURL"`
+		Home         string `json:"home" yaml:"home"`
+		Template     string `json:"template" yaml:"template"`
+		Password     Secret `json:"password" yaml:"password"`
+		Nested       struct {
+			Path  string   `json:"path" yaml:"path"`
+			Paths []string `json:"paths" yaml:"paths"`
+		} `json:"nested" yaml:"nested"`
+	}
+)
+
+func TestInterpolation(t *testing.T) {
+	os.Setenv("APP_TEST_HOME", "/home/app")
+	defer os.Unsetenv("APP_TEST_HOME")
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("YAMLSource.Load", func(t *testing.T) {
+		path := write("config.yaml", `
+postgresURL: postgres://${postgresHost}:${postgresPort}/${DB_NAME_UNSET:-app}
+postgresHost: ${POSTGRES_HOST_UNSET:-localhost}
+postgresPort: 5432
+home: ${APP_TEST_HOME}
+template: $${postgresHost}
+nested:
+  path: ${home}/data
+  paths: ["${nested.path}/a", "${Home}/b"]
+`)
+		config := new(InterpolationConfig)
+		if err := (YAMLSource{path}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresURL != "postgres://localhost:5432/app" {
+			t.Errorf("PostgresURL = %s, want %s", config.PostgresURL, "postgres://localhost:5432/app")
+		}
+		if config.Template != "${postgresHost}" {
+			t.Errorf("Template = %s, want %s", config.Template, "${postgresHost}")
+		}
+		if config.Nested.Path != "/home/app/data" {
+			t.Errorf("Nested.Path = %s, want %s", config.Nested.Path, "/home/app/data")
+		}
+		if len(config.Nested.Paths) != 2 || config.Nested.Paths[0] != "/home/app/data/a" || config.Nested.Paths[1] != "/home/app/b" {
+			t.Errorf("Nested.Paths = %v, want %s", config.Nested.Paths, "[/home/app/data/a /home/app/b]")
+		}
+
+		// values loaded by the previous source are not expanded again
+		path = write("config.json", `{"postgresHost": "db"}`)
+		if err := (JSONSource{path}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.Template != "${postgresHost}" {
+			t.Errorf("Template = %s, want %s", config.Template, "${postgresHost}")
+		}
+	})
+
+	t.Run("DirSource.Load", func(t *testing.T) {
+		configDir := filepath.Join(dir, "config")
+		if err := os.Mkdir(configDir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(configDir, "postgres-url"), []byte("${APP_TEST_HOME}/db.sock"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		config := new(InterpolationConfig)
+		if err := (DirSource{configDir}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresURL != "/home/app/db.sock" {
+			t.Errorf("PostgresURL = %s, want %s", config.PostgresURL, "/home/app/db.sock")
+		}
+	})
+
+	t.Run("required", func(t *testing.T) {
+		path := write("required.json", `{"postgresHost": "${POSTGRES_HOST_UNSET:?postgres host is required}"}`)
+		err := (JSONSource{path}).Load(new(InterpolationConfig))
+		if !errors.Is(err, ErrRequiredVariable) {
+			t.Errorf("Error = %v, want %s", err, ErrRequiredVariable)
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		path := write("cycle.json", `{"postgresHost": "${postgresURL}", "postgresURL": "${postgresHost}"}`)
+		err := (JSONSource{path}).Load(new(InterpolationConfig))
+		if !errors.Is(err, ErrInterpolationCycle) {
+			t.Errorf("Error = %v, want %s", err, ErrInterpolationCycle)
+		}
+	})
+	t.Run("invalid reference", func(t *testing.T) {
+		path := write("invalid.json", `{"postgresHost": "hunter2${postgresPort"}`)
+		err := (JSONSource{path}).Load(new(InterpolationConfig))
+		if !errors.Is(err, ErrInvalidReference) {
+			t.Errorf("Error = %v, want %s", err, ErrInvalidReference)
+		}
+		if err != nil && (strings.Contains(err.Error(), "hunter2") || !strings.Contains(err.Error(), "PostgresHost")) {
+			t.Errorf("Error = %s, want field path and reference name only", err.Error())
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		Interpolation = false
+		defer func() { Interpolation = true }()
+		path := write("literal.json", `{"postgresHost": "a${b", "postgresURL": "${postgresHost}"}`)
+		config := new(InterpolationConfig)
+		if err := (JSONSource{path}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresHost != "a${b" || config.PostgresURL != "${postgresHost}" {
+			t.Errorf("Config = %+v, want literal values", config)
+		}
+	})
+
+	t.Run("secret reference", func(t *testing.T) {
+		path := write("secret-ref.yaml", "password: hunter2\npostgresURL: postgres://u:${password}@h\n")
+		config := new(InterpolationConfig)
+		err := (YAMLSource{path}).Load(config)
+		if !errors.Is(err, ErrSecretReference) {
+			t.Errorf("Error = %v, want %s", err, ErrSecretReference)
+		}
+		if redacted := fmt.Sprintf("%+v", Redact(config)); strings.Contains(redacted, "hunter2") {
+			t.Errorf("Redact = %s, must not contain the secret", redacted)
+		}
+	})
+
+	t.Run("secret and decrypted values", func(t *testing.T) {
+		key, err := GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Setenv(KeyEnv, key)
+		defer os.Unsetenv(KeyEnv)
+		encrypted, err := Encrypt(EncryptAESGCM, key, "pa$${ss${word")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		path := write("secret.yaml", "password: hunter2${oops\ntemplate: "+encrypted+"\n")
+		config := new(InterpolationConfig)
+		if err := (YAMLSource{path}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.Password != "hunter2${oops" {
+			t.Errorf("Password = %s, want %s", config.Password, "hunter2${oops")
+		}
+		if config.Template != "pa$${ss${word" {
+			t.Errorf("Template = %s, want %s", config.Template, "pa$${ss${word")
+		}
+
+		configDir := filepath.Join(dir, "secret")
+		if err := os.Mkdir(configDir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(configDir, "template"), []byte(encrypted), 0600); err != nil {
+			t.Fatal(err)
+		}
+		config = new(InterpolationConfig)
+		if err := (DirSource{configDir}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.Template != "pa$${ss${word" {
+			t.Errorf("Template = %s, want %s", config.Template, "pa$${ss${word")
+		}
+	})
+}
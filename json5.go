@@ -0,0 +1,349 @@
+package easyconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// JSON5Source loads configuration from the given .json5 or .jsonc file: JSON with comments,
+// trailing commas, unquoted keys, single-quoted strings and JSON5 numbers, bound through json tags.
+type JSON5Source struct {
+	Path string
+}
+
+const ErrJSON5Syntax strErr = "invalid json5 syntax"
+
+// json5Converter rewrites JSON5 into JSON, offsets keep the input offset of every output byte
+type json5Converter struct {
+	in      []byte
+	pos     int
+	out     bytes.Buffer
+	offsets []int
+}
+
+func init() {
+	registerFormat(fileFormat{name: "json5", exts: []string{".json5", ".jsonc"}, decoder: decodeJSON5, sniffer: sniffJSON5})
+}
+
+// Load JSON5 configuration file
+func (s JSON5Source) Load(structPtr interface{}) error {
+	data, err := readFile(s.Path)
+	if err != nil {
+		return err
+	}
+	return s.decode(nil, data, structPtr)
+}
+
+func (s JSON5Source) filePath() string { return s.Path }
+
+func (s JSON5Source) decode(fsys fs.FS, data []byte, structPtr interface{}) error {
+	return includeFile(fsys, nil, "json5", s.Path, data, structPtr)
+}
+
+// sniffJSON5 detects JSON5 content that is not plain JSON
+func sniffJSON5(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	if json.Valid(trimmed) || !(bytes.HasPrefix(trimmed, []byte("{")) || bytes.HasPrefix(trimmed, []byte("//")) || bytes.HasPrefix(trimmed, []byte("/*"))) {
+		return false
+	}
+	converted, _, err := json5ToJSON(data)
+	return err == nil && json.Valid(converted)
+}
+
+func decodeJSON5(data []byte, structPtr interface{}) error {
+	converted, offsets, err := json5ToJSON(data)
+	if err != nil {
+		return err
+	}
+	return decodeJSONMapped(data, converted, offsets, structPtr)
+}
+
+// json5ToJSON converts JSON5 to JSON, returned offsets map output bytes to input offsets
+func json5ToJSON(data []byte) ([]byte, []int, error) {
+	c := &json5Converter{in: data}
+	for {
+		if err := c.skipSpace(); err != nil {
+			return nil, nil, err
+		}
+		if c.pos >= len(c.in) {
+			break
+		}
+		if err := c.token(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return c.out.Bytes(), c.offsets, nil
+}
+
+func (c *json5Converter) emit(s string, offset int) {
+	c.out.WriteString(s)
+	for i := 0; i < len(s); i++ {
+		c.offsets = append(c.offsets, offset)
+	}
+}
+
+func (c *json5Converter) errorf(offset int, format string, args ...interface{}) error {
+	return positionError(c.in, offset, fmt.Errorf("%w: "+format, append([]interface{}{ErrJSON5Syntax}, args...)...))
+}
+
+// skipSpace skips whitespace and comments
+func (c *json5Converter) skipSpace() error {
+	for c.pos < len(c.in) {
+		switch {
+		case strings.IndexByte(" \t\r\n\f\v", c.in[c.pos]) >= 0:
+			c.pos++
+		case bytes.HasPrefix(c.in[c.pos:], []byte("\xef\xbb\xbf")) || bytes.HasPrefix(c.in[c.pos:], []byte(" ")):
+			_, size := utf8.DecodeRune(c.in[c.pos:])
+			c.pos += size
+		case bytes.HasPrefix(c.in[c.pos:], []byte("//")):
+			end := bytes.IndexByte(c.in[c.pos:], '\n')
+			if end < 0 {
+				c.pos = len(c.in)
+			} else {
+				c.pos += end + 1
+			}
+		case bytes.HasPrefix(c.in[c.pos:], []byte("/*")):
+			end := bytes.Index(c.in[c.pos+2:], []byte("*/"))
+			if end < 0 {
+				return c.errorf(c.pos, "unterminated comment")
+			}
+			c.pos += end + 4
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (c *json5Converter) token() error {
+	start := c.pos
+	ch := c.in[c.pos]
+	switch {
+	case strings.IndexByte("{}[]:", ch) >= 0:
+		c.emit(string(ch), start)
+		c.pos++
+	case ch == ',':
+		c.pos++
+		if err := c.skipSpace(); err != nil {
+			return err
+		}
+		// trailing comma
+		if c.pos < len(c.in) && (c.in[c.pos] == '}' || c.in[c.pos] == ']') {
+			return nil
+		}
+		c.emit(",", start)
+	case ch == '"' || ch == '\'':
+		return c.str()
+	case ch == '-' || ch == '+' || ch == '.' || (ch >= '0' && ch <= '9'):
+		return c.number()
+	case isIdentStart(ch):
+		end := c.pos
+		for end < len(c.in) && (isIdentStart(c.in[end]) || (c.in[end] >= '0' && c.in[end] <= '9')) {
+			end++
+		}
+		ident := string(c.in[c.pos:end])
+		c.pos = end
+		if err := c.skipSpace(); err != nil {
+			return err
+		}
+		// identifiers are allowed only as object keys
+		isKey := c.pos < len(c.in) && c.in[c.pos] == ':'
+		switch {
+		case isKey:
+			c.emit(strconv.Quote(ident), start)
+		case ident == "true" || ident == "false" || ident == "null":
+			c.emit(ident, start)
+		case ident == "Infinity" || ident == "NaN":
+			return c.errorf(start, "%s is not supported", ident)
+		default:
+			return c.errorf(start, "unquoted string %s", ident)
+		}
+	default:
+		return c.errorf(start, "unexpected character %q", ch)
+	}
+	return nil
+}
+
+func isIdentStart(ch byte) bool {
+	return ch == '_' || ch == '$' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+// str converts single or double quoted JSON5 string to JSON string
+func (c *json5Converter) str() error {
+	start := c.pos
+	quote := c.in[c.pos]
+	c.pos++
+	var b strings.Builder
+	for {
+		if c.pos >= len(c.in) || c.in[c.pos] == '\n' {
+			return c.errorf(start, "unterminated string")
+		}
+		ch := c.in[c.pos]
+		c.pos++
+		if ch == quote {
+			break
+		}
+		if ch != '\\' {
+			b.WriteByte(ch)
+			continue
+		}
+		if c.pos >= len(c.in) {
+			return c.errorf(start, "unterminated string")
+		}
+		esc := c.in[c.pos]
+		c.pos++
+		switch esc {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'v':
+			b.WriteByte('\v')
+		case '0':
+			b.WriteByte(0)
+		case '\r':
+			if c.pos < len(c.in) && c.in[c.pos] == '\n' {
+				c.pos++
+			}
+		case '\n':
+			// line continuation
+		case 'x', 'u':
+			size := 2
+			if esc == 'u' {
+				size = 4
+			}
+			if c.pos+size > len(c.in) {
+				return c.errorf(c.pos-2, "malformed escape")
+			}
+			r, err := strconv.ParseUint(string(c.in[c.pos:c.pos+size]), 16, 32)
+			if err != nil {
+				return c.errorf(c.pos-2, "malformed escape")
+			}
+			c.pos += size
+			// \uD83D\uDE00 surrogate pair
+			if esc == 'u' && utf16.IsSurrogate(rune(r)) && bytes.HasPrefix(c.in[c.pos:], []byte("\\u")) && c.pos+6 <= len(c.in) {
+				if low, err := strconv.ParseUint(string(c.in[c.pos+2:c.pos+6]), 16, 32); err == nil {
+					if pair := utf16.DecodeRune(rune(r), rune(low)); pair != utf8.RuneError {
+						r = uint64(pair)
+						c.pos += 6
+					}
+				}
+			}
+			b.WriteRune(rune(r))
+		default:
+			b.WriteByte(esc)
+		}
+	}
+	quoted, _ := json.Marshal(b.String())
+	c.emit(string(quoted), start)
+	return nil
+}
+
+// number converts JSON5 numbers (+1, .5, 5., 0x1F) to JSON numbers
+func (c *json5Converter) number() error {
+	start := c.pos
+	end := c.pos
+	for end < len(c.in) && strings.IndexByte("0123456789abcdefABCDEFxX.+-", c.in[end]) >= 0 {
+		if (c.in[end] == '+' || c.in[end] == '-') && end > start && c.in[end-1] != 'e' && c.in[end-1] != 'E' {
+			break
+		}
+		end++
+	}
+	num := string(c.in[start:end])
+	c.pos = end
+
+	sign := ""
+	switch {
+	case strings.HasPrefix(num, "+"):
+		num = num[1:]
+	case strings.HasPrefix(num, "-"):
+		sign, num = "-", num[1:]
+	}
+	if num == "Infinity" || (num == "" && end < len(c.in) && c.in[end] == 'I') {
+		return c.errorf(start, "Infinity is not supported")
+	}
+	if strings.HasPrefix(num, "0x") || strings.HasPrefix(num, "0X") {
+		n, err := strconv.ParseUint(num[2:], 16, 64)
+		if err != nil {
+			return c.errorf(start, "invalid number %s", num)
+		}
+		c.emit(sign+strconv.FormatUint(n, 10), start)
+		return nil
+	}
+	if strings.HasPrefix(num, ".") {
+		num = "0" + num
+	}
+	if i := strings.Index(num, "."); i >= 0 && (i == len(num)-1 || num[i+1] < '0' || num[i+1] > '9') {
+		num = num[:i+1] + "0" + num[i+1:]
+	}
+	if _, err := strconv.ParseFloat(num, 64); err != nil {
+		return c.errorf(start, "invalid number %s", num)
+	}
+	c.emit(sign+num, start)
+	return nil
+}
+
+// jsonPositionError adds line and column to encoding/json errors with byte offsets of data,
+// offsets map data bytes to source offsets (nil when data is the source)
+func jsonPositionError(source, data []byte, offsets []int, err error) error {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+		if offset > 0 {
+			offset--
+		}
+	case errors.As(err, &typeErr):
+		offset = int64(jsonValueStart(data, int(typeErr.Offset)))
+	default:
+		return err
+	}
+	if offsets == nil {
+		return positionError(source, int(offset), err)
+	}
+	if len(offsets) == 0 {
+		return positionError(source, 0, err)
+	}
+	if int(offset) >= len(offsets) {
+		offset = int64(len(offsets) - 1)
+	}
+	return positionError(source, offsets[offset], err)
+}
+
+// jsonValueStart returns the start of the string or scalar value ending at the offset
+func jsonValueStart(data []byte, end int) int {
+	if end > len(data) {
+		end = len(data)
+	}
+	if end == 0 {
+		return 0
+	}
+	start := end - 1
+	if data[start] == '"' {
+		for start--; start >= 0; start-- {
+			if data[start] == '"' && (start == 0 || data[start-1] != '\\') {
+				return start
+			}
+		}
+		return 0
+	}
+	for start > 0 && strings.IndexByte(",:[{ \t\r\n", data[start-1]) < 0 {
+		start--
+	}
+	return start
+}
@@ -0,0 +1,77 @@
+package easyconfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestJSON5SourceLoader(t *testing.T) {
+	t.Run("JSON5Source.Load", func(t *testing.T) {
+		config := new(Config)
+		if err := (JSON5Source{"tests/config.json5"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "postgres" {
+			t.Errorf("PostgresUser = %s, want %s", config.PostgresUser, "postgres")
+		}
+		if config.PostgresPassword != `pass"word` {
+			t.Errorf("PostgresPassword = %s, want %s", config.PostgresPassword, `pass"word`)
+		}
+		if config.PostgresHost != "localhost" {
+			t.Errorf("PostgresHost = %s, want %s", config.PostgresHost, "localhost")
+		}
+		if config.PostgresPort != 5432 {
+			t.Errorf("PostgresPort = %d, want %d", config.PostgresPort, 5432)
+		}
+		if fmt.Sprintf("%v", config.Slice) != "[a1 a2 a3 a4 a5]" {
+			t.Errorf("Slice = %v, want %s", config.Slice, "[a1 a2 a3 a4 a5]")
+		}
+	})
+
+	t.Run("FileSource.Load", func(t *testing.T) {
+		config := new(Config)
+		if err := (FileSource{Path: "tests/config.json5"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresDBName != "db-name" {
+			t.Errorf("PostgresDBName = %s, want %s", config.PostgresDBName, "db-name")
+		}
+		if sniffFormat([]byte("{\n  // comment\n  a: 1,\n}")) != "json5" {
+			t.Errorf("sniffFormat = %s, want %s", sniffFormat([]byte("{\n  // comment\n  a: 1,\n}")), "json5")
+		}
+	})
+
+	t.Run("escapes", func(t *testing.T) {
+		config := new(Config)
+		if err := decodeJSON5([]byte(`{postgresUser: '\uD83D\uDE00 \u00e9\x41', postgresHost: "\uD83D!"}`), config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "\U0001F600 \u00e9A" {
+			t.Errorf("PostgresUser = %q, want %q", config.PostgresUser, "\U0001F600 \u00e9A")
+		}
+		if config.PostgresHost != "\uFFFD!" {
+			t.Errorf("PostgresHost = %q, want %q", config.PostgresHost, "\uFFFD!")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		err := decodeJSON5([]byte("{\n  // comment\n  postgresUser: 'postgres\n}"), new(Config))
+		if !errors.Is(err, ErrJSON5Syntax) || !strings.HasPrefix(err.Error(), "line 3, column 17:") {
+			t.Errorf("Error = %v, want %s at line 3, column 17", err, ErrJSON5Syntax)
+		}
+		err = decodeJSON5([]byte("{\n  postgresUser: 'postgres',\n  postgresPort: 'port',\n}"), new(Config))
+		if err == nil || !strings.HasPrefix(err.Error(), "line 3, column 17:") {
+			t.Errorf("Error = %v, want type error at line 3, column 17", err)
+		}
+		err = decodeJSON5([]byte("{\n  postgresUser: postgres,\n}"), new(Config))
+		if !errors.Is(err, ErrJSON5Syntax) || !strings.HasPrefix(err.Error(), "line 2, column 17:") {
+			t.Errorf("Error = %v, want %s at line 2, column 17", err, ErrJSON5Syntax)
+		}
+		err = decodeJSON([]byte("{\n  \"postgresUser\": \"postgres\",\n  \"postgresPort\" 5432\n}"), new(Config))
+		if err == nil || !strings.HasPrefix(err.Error(), "line 3, column 18:") {
+			t.Errorf("Error = %v, want syntax error at line 3, column 18", err)
+		}
+	})
+}
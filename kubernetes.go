@@ -0,0 +1,91 @@
+package easyconfig
+
+import (
+	"bytes"
+	"encoding/base64"
+	"reflect"
+	"strings"
+)
+
+type (
+	// KubernetesManifest generates ConfigMap and Secret manifests from a config struct.
+	// Fields tagged `secret:"true"` go to the Secret, all others to the ConfigMap.
+	// Keys match DirSource file names, or EnvSource variable names when Env is set
+	// (for use with envFrom).
+	KubernetesManifest struct {
+		Name      string
+		Namespace string
+		Env       bool
+		Prefix    string
+	}
+
+	k8sObject struct {
+		APIVersion string            `yaml:"apiVersion"`
+		Kind       string            `yaml:"kind"`
+		Metadata   k8sMetadata       `yaml:"metadata"`
+		Type       string            `yaml:"type,omitempty"`
+		Data       map[string]string `yaml:"data"`
+	}
+
+	k8sMetadata struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace,omitempty"`
+	}
+)
+
+// Manifests returns ConfigMap and Secret YAML documents separated by "---"
+func (m KubernetesManifest) Manifests(structPtr interface{}) ([]byte, error) {
+	configMap, err := m.ConfigMap(structPtr)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := m.Secret(structPtr)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.Join([][]byte{configMap, secret}, []byte("---\n")), nil
+}
+
+// ConfigMap returns ConfigMap YAML with non-secret fields of structPtr
+func (m KubernetesManifest) ConfigMap(structPtr interface{}) ([]byte, error) {
+	return marshalYAML(k8sObject{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Metadata:   k8sMetadata{Name: m.Name, Namespace: m.Namespace},
+		Data:       m.data(structPtr, false),
+	})
+}
+
+// Secret returns Secret YAML with base64-encoded secret fields of structPtr
+func (m KubernetesManifest) Secret(structPtr interface{}) ([]byte, error) {
+	data := m.data(structPtr, true)
+	for key, value := range data {
+		data[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	return marshalYAML(k8sObject{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata:   k8sMetadata{Name: m.Name, Namespace: m.Namespace},
+		Type:       "Opaque",
+		Data:       data,
+	})
+}
+
+func (m KubernetesManifest) data(structPtr interface{}, secret bool) map[string]string {
+	tag, prefix := "dir", ""
+	if m.Env {
+		tag, prefix = "env", m.Prefix
+	}
+	data := map[string]string{}
+	structElem := reflect.ValueOf(structPtr).Elem()
+	for i := 0; i < structElem.NumField(); i++ {
+		field := structElem.Type().Field(i)
+		tagVal := strings.TrimSpace(field.Tag.Get(tag))
+		if field.PkgPath != "" || tagVal == "-" || field.Type.Kind() == reflect.Struct || isSecret(field) != secret {
+			continue
+		}
+		key, separator := convertName(field.Name, tag, tagVal, prefix)
+		data[key] = valueString(structElem.Field(i), separator)
+	}
+	return data
+}
@@ -0,0 +1,69 @@
+package easyconfig
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestKubernetesManifest(t *testing.T) {
+	config := &ExampleConfig{
+		PostgresUser:     "postgres",
+		PostgresPassword: "password",
+		PostgresPort:     5432,
+		Slice:            []string{"a1", "a2"},
+	}
+
+	t.Run("KubernetesManifest.ConfigMap", func(t *testing.T) {
+		data, err := KubernetesManifest{Name: "app"}.ConfigMap(config)
+		if err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		obj := new(k8sObject)
+		if err := yaml.Unmarshal(data, obj); err != nil {
+			t.Fatal(err)
+		}
+		if obj.Kind != "ConfigMap" || obj.Metadata.Name != "app" {
+			t.Errorf("Kind = %s, Name = %s, want %s, %s", obj.Kind, obj.Metadata.Name, "ConfigMap", "app")
+		}
+		if _, ok := obj.Data["postgres-password"]; ok {
+			t.Errorf("ConfigMap contains secret field postgres-password")
+		}
+
+		// the ConfigMap mounted as a directory must be readable by DirSource
+		dir := t.TempDir()
+		for key, value := range obj.Data {
+			if err := ioutil.WriteFile(filepath.Join(dir, key), []byte(value), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		loaded := new(ExampleConfig)
+		if err := (DirSource{dir}).Load(loaded); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if loaded.PostgresUser != "postgres" || loaded.PostgresPort != 5432 || len(loaded.Slice) != 2 {
+			t.Errorf("Loaded = %+v, want values from the ConfigMap", loaded)
+		}
+	})
+
+	t.Run("KubernetesManifest.Secret", func(t *testing.T) {
+		data, err := KubernetesManifest{Name: "app", Env: true, Prefix: "APP"}.Secret(config)
+		if err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		obj := new(k8sObject)
+		if err := yaml.Unmarshal(data, obj); err != nil {
+			t.Fatal(err)
+		}
+		if obj.Kind != "Secret" || len(obj.Data) != 1 {
+			t.Fatalf("Kind = %s, Data = %v, want Secret with one key", obj.Kind, obj.Data)
+		}
+		value, _ := base64.StdEncoding.DecodeString(obj.Data["APP_POSTGRES_PASSWORD"])
+		if string(value) != "password" {
+			t.Errorf("APP_POSTGRES_PASSWORD = %s, want %s", value, "password")
+		}
+	})
+}
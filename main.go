@@ -1,9 +1,12 @@
 package easyconfig
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -15,7 +18,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/iancoleman/strcase"
 	"github.com/joho/godotenv"
-	"gopkg.in/yaml.v2"
+	yaml "gopkg.in/yaml.v3"
 	"olympos.io/encoding/edn"
 )
 
@@ -33,9 +36,11 @@ type (
 	}
 
 	// FileSource satisifies the loader interface. It loads the
-	// configuration from the given file.
+	// configuration from the given file. Format (json, yaml, toml, edn, env or a registered
+	// format) overrides the file extension, files of unknown type are detected by content.
 	FileSource struct {
-		Path string
+		Path   string
+		Format string
 	}
 
 	// FileSource loads configuration from the given .json file.
@@ -63,6 +68,25 @@ type (
 		Path string
 	}
 
+	// DockerSecretsSource loads configuration from the Docker secrets directory
+	// (/run/secrets by default, skipped when missing), file names are snake_case field names.
+	DockerSecretsSource struct {
+		Path string
+	}
+
+	// SystemdCredentialsSource loads configuration from systemd credentials
+	// ($CREDENTIALS_DIRECTORY), file names are the same as for DirSource.
+	SystemdCredentialsSource struct {
+	}
+
+	// ConfDirSource loads files of registered formats (*.json, *.yaml, *.yml, *.toml, *.edn,
+	// *.env, see RegisterFormat) of the directory (conf.d) in lexical order, later files override earlier ones.
+	// Prefix is used for .env files, the source is skipped when the directory does not exist.
+	ConfDirSource struct {
+		Path   string
+		Prefix string
+	}
+
 	// EnvFileSource loads configuration from the given .env file.
 	EnvFileSource struct {
 		Prefix string
@@ -78,6 +102,13 @@ type (
 	FlagsSource struct {
 	}
 
+	// fileSource is implemented by sources reading a single file,
+	// so wrappers can check the content before it is decoded.
+	fileSource interface {
+		filePath() string
+		decode(fsys fs.FS, data []byte, structPtr interface{}) error
+	}
+
 	strErr string
 )
 
@@ -93,6 +124,9 @@ func (e strErr) Error() string {
 const (
 	ErrIsDirectory     strErr = "file is a directory"
 	ErrUnknownFileType strErr = "unknown file type"
+	ErrFileTooLarge    strErr = "file is too large"
+
+	maxFileSize = 10485760 // 10 Mb
 )
 
 func (c *errCollector) Collect(e error) {
@@ -142,6 +176,33 @@ func (l Loader) Load(structPtr interface{}) error {
 	return errs.Error()
 }
 
+// Provenance loads configuration like Load and returns the source that set each field
+// (Go names, nested fields joined with "."), ProfileSource reports its active profile
+func (l Loader) Provenance(structPtr interface{}) (map[string]string, error) {
+	provenance := map[string]string{}
+	errs := new(errCollector)
+	for _, src := range l.Sources {
+		before := snapshot(structPtr)
+		errs.Collect(src.Load(structPtr))
+		for path := range changedPaths(before, reflect.ValueOf(structPtr).Elem(), "") {
+			provenance[path] = sourceName(src)
+		}
+	}
+	return provenance, errs.Error()
+}
+
+// sourceName describes the source: fmt.Stringer, the path of file sources or the type name
+func sourceName(src Source) string {
+	if s, ok := src.(fmt.Stringer); ok {
+		return s.String()
+	}
+	if s, ok := src.(fileSource); ok {
+		return s.filePath()
+	}
+	name := strings.TrimPrefix(fmt.Sprintf("%T", src), "*")
+	return strings.TrimPrefix(name, "easyconfig.")
+}
+
 // Load configuration
 func (l Loader) Help(structPtr interface{}) {
 	structElem := reflect.ValueOf(structPtr).Elem()
@@ -149,8 +210,15 @@ func (l Loader) Help(structPtr interface{}) {
 	fromEnv := false
 	fromFlag := false
 	fromDir := false
+	fromDocker := false
+	fromFile := false
 	prefix := ""
 	for _, source := range l.Sources {
+		switch source.(type) {
+		case FileSource, *FileSource, ConfDirSource, *ConfDirSource, ProfileSource, *ProfileSource,
+			SearchSource, *SearchSource, ConfigPathSource, *ConfigPathSource:
+			fromFile = true
+		}
 		if t, ok := source.(EnvFileSource); ok {
 			fromEnv = true
 			prefix = t.Prefix
@@ -185,6 +253,18 @@ func (l Loader) Help(structPtr interface{}) {
 		if _, ok := source.(*FileSource); ok {
 			fromDir = true
 		}
+		if _, ok := source.(SystemdCredentialsSource); ok {
+			fromDir = true
+		}
+		if _, ok := source.(*SystemdCredentialsSource); ok {
+			fromDir = true
+		}
+		if _, ok := source.(DockerSecretsSource); ok {
+			fromDocker = true
+		}
+		if _, ok := source.(*DockerSecretsSource); ok {
+			fromDocker = true
+		}
 	}
 	if fromFlag {
 		tags = append(tags, "flag")
@@ -195,15 +275,39 @@ func (l Loader) Help(structPtr interface{}) {
 	if fromDir {
 		tags = append(tags, "dir")
 	}
+	if fromDocker {
+		tags = append(tags, "docker")
+	}
 
 	fmt.Println(l.HelpMSG)
 
+	if fromFile {
+		fmt.Printf("\nConfiguration file formats: %s\n", strings.Join(Formats(), ", "))
+	}
+
+	for _, source := range l.Sources {
+		if t, ok := source.(ProfileSource); ok {
+			t.help()
+		}
+		if t, ok := source.(*ProfileSource); ok {
+			t.help()
+		}
+		if t, ok := source.(ConfigPathSource); ok {
+			t.help(structPtr)
+		}
+		if t, ok := source.(*ConfigPathSource); ok {
+			t.help(structPtr)
+		}
+	}
+
 	for _, tag := range tags {
 		switch tag {
 		case "flag":
 			fmt.Printf("\nThe commands are:\n\n")
 		case "dir":
 			fmt.Printf("\nConfiguration directory files to use:\n\n")
+		case "docker":
+			fmt.Printf("\nDocker secrets to use:\n\n")
 		default:
 			fmt.Printf("\nEnvironment variables to use:\n\n")
 		}
@@ -216,7 +320,12 @@ func (l Loader) Help(structPtr interface{}) {
 			}
 			fieldName, _ := convertName(field.Name, tag, tagVal, prefix)
 
-			bold.Printf("    %s\n", fieldName)
+			bold.Printf("    %s", fieldName)
+			if isSecret(field) {
+				fmt.Print(" (secret)")
+				elem = nil // never reveal secret defaults
+			}
+			fmt.Println()
 			if tag == "flag" {
 				printed := false
 				switch v := elem.(type) {
@@ -245,110 +354,107 @@ func (l Loader) Help(structPtr interface{}) {
 
 // Load config from file
 func (s FileSource) Load(structPtr interface{}) error {
-	if info, err := os.Stat(s.Path); err != nil {
-		return err
-	} else {
-		if info.IsDir() {
-			return (&DirSource{Path: s.Path}).Load(structPtr)
-		}
-		ext := filepath.Ext(s.Path)
-		switch ext {
-		case ".json":
-			return (&JSONSource{Path: s.Path}).Load(structPtr)
-		case ".yaml", ".yml":
-			return (&YAMLSource{Path: s.Path}).Load(structPtr)
-		case ".env":
-			return (&EnvFileSource{Path: s.Path}).Load(structPtr)
-		case ".toml":
-			return (&TOMLSource{Path: s.Path}).Load(structPtr)
-		case ".edn":
-			return (&EDNSource{Path: s.Path}).Load(structPtr)
-		default:
-			if err := (&JSONSource{Path: s.Path}).Load(structPtr); err == nil {
-				return nil
-			}
-			if err := (&YAMLSource{Path: s.Path}).Load(structPtr); err == nil {
-				return nil
-			}
-			if err := (&EnvFileSource{Path: s.Path}).Load(structPtr); err == nil {
-				return nil
-			}
-			if err := (&TOMLSource{Path: s.Path}).Load(structPtr); err == nil {
-				return nil
-			}
-			return ErrUnknownFileType
+	return s.load(nil, structPtr)
+}
+
+func (s FileSource) load(fsys fs.FS, structPtr interface{}) error {
+	if baseFS(fsys) != nil || s.Path != "-" {
+		if info, err := statFS(fsys, s.Path); err != nil {
+			return err
+		} else if info.IsDir() {
+			return DirSource{Path: s.Path}.load(fsys, structPtr)
 		}
 	}
+	data, err := readFileFS(fsys, s.Path)
+	if err != nil {
+		return err
+	}
+	return s.decode(fsys, data, structPtr)
 }
 
 // Load JSON configuration file
 func (s JSONSource) Load(structPtr interface{}) error {
-	file, err := getFile(s.Path)
+	data, err := readFile(s.Path)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-	return json.NewDecoder(file).Decode(structPtr)
+	return s.decode(nil, data, structPtr)
 }
 
 // Load YAML configuration file
 func (s YAMLSource) Load(structPtr interface{}) error {
-	file, err := getFile(s.Path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	data, err := ioutil.ReadAll(file)
+	data, err := readFile(s.Path)
 	if err != nil {
 		return err
 	}
-
-	return yaml.Unmarshal(data, structPtr)
+	return s.decode(nil, data, structPtr)
 }
 
 // Load TOML configuration file
 func (s TOMLSource) Load(structPtr interface{}) error {
-	file, err := getFile(s.Path)
+	data, err := readFile(s.Path)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	_, err = toml.DecodeReader(file, structPtr)
-	return err
+	return s.decode(nil, data, structPtr)
 }
 
 // Load EDN configuration file
 func (s EDNSource) Load(structPtr interface{}) error {
-	file, err := getFile(s.Path)
+	data, err := readFile(s.Path)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	data, err := ioutil.ReadAll(file)
-	if err != nil {
-		return err
-	}
-
-	return edn.Unmarshal(data, structPtr)
+	return s.decode(nil, data, structPtr)
 }
 
 // Load ENV configuration file
 func (s EnvFileSource) Load(structPtr interface{}) error {
-	file, err := getFile(s.Path)
+	data, err := readFile(s.Path)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	return s.decode(nil, data, structPtr)
+}
 
-	envMap, err := godotenv.Parse(file)
-	if err != nil {
-		return err
+func (s FileSource) filePath() string    { return s.Path }
+func (s JSONSource) filePath() string    { return s.Path }
+func (s YAMLSource) filePath() string    { return s.Path }
+func (s TOMLSource) filePath() string    { return s.Path }
+func (s EDNSource) filePath() string     { return s.Path }
+func (s EnvFileSource) filePath() string { return s.Path }
+
+func (s FileSource) decode(fsys fs.FS, data []byte, structPtr interface{}) error {
+	format := formatOf(s.Path)
+	if s.Format != "" {
+		if format = formatName(s.Format); format == "" {
+			return fmt.Errorf("%s: %w", s.Format, ErrUnknownFileType)
+		}
 	}
+	return includeFile(fsys, nil, format, s.Path, data, structPtr)
+}
 
-	return map2struct("env", s.Prefix, envMap, structPtr)
+func (s JSONSource) decode(fsys fs.FS, data []byte, structPtr interface{}) error {
+	return includeFile(fsys, nil, "json", s.Path, data, structPtr)
+}
+
+func (s YAMLSource) decode(fsys fs.FS, data []byte, structPtr interface{}) error {
+	return includeFile(fsys, nil, "yaml", s.Path, data, structPtr)
+}
+
+func (s TOMLSource) decode(fsys fs.FS, data []byte, structPtr interface{}) error {
+	return includeFile(fsys, nil, "toml", s.Path, data, structPtr)
+}
+
+func (s EDNSource) decode(fsys fs.FS, data []byte, structPtr interface{}) error {
+	return includeFile(fsys, nil, "edn", s.Path, data, structPtr)
+}
+
+func (s EnvFileSource) decode(fsys fs.FS, data []byte, structPtr interface{}) error {
+	if err := decodeEnv(s.Prefix, data, structPtr); err != nil {
+		return fileError(fsys, s.Path, data, structPtr, err)
+	}
+	return nil
 }
 
 // Load configuration from environment variables
@@ -360,34 +466,122 @@ func (s EnvSource) Load(structPtr interface{}) error {
 			envMap[p[0]] = p[1]
 		}
 	}
+	if err := readFileVars(s.Prefix, envMap, structPtr); err != nil {
+		return err
+	}
 	return map2struct("env", s.Prefix, envMap, structPtr)
 }
 
-// Load configuration from environment variables
+// Load configuration from kubernetes ConfigMap or Secret directory
 func (s DirSource) Load(structPtr interface{}) error {
-	dirMap := map[string]string{}
-	files, err := ioutil.ReadDir(s.Path)
+	return s.load(nil, structPtr)
+}
+
+func (s DirSource) load(fsys fs.FS, structPtr interface{}) error {
+	dirMap, err := readDir(fsys, s.Path, false)
 	if err != nil {
 		return err
 	}
+	encrypted := map[string]string{}
+	for key, value := range dirMap {
+		if isEncrypted(strings.TrimSpace(value)) {
+			encrypted[key] = value
+		}
+	}
+	if err := decryptMap(dirMap); err != nil {
+		return err
+	}
+	before := snapshot(structPtr)
+	if err := map2struct("dir", "", dirMap, structPtr); err != nil {
+		return err
+	}
 
-	for _, file := range files {
-		if !file.IsDir() && file.Size() < 10485760 { // 10 Mb
-			data, err := ioutil.ReadFile(filepath.Join(s.Path, file.Name()))
-			if err != nil {
-				continue
-			}
-			dirMap[file.Name()] = string(data)
+	// decrypted values are not expanded
+	for key := range encrypted {
+		encrypted[key] = dirMap[key]
+	}
+	decrypted := reflect.New(reflect.TypeOf(structPtr).Elem())
+	if err := map2struct("dir", "", encrypted, decrypted.Interface()); err != nil {
+		return err
+	}
+	zero := reflect.New(decrypted.Elem().Type()).Elem()
+	return interpolateStruct(before, structPtr, changedPaths(zero, decrypted.Elem(), ""))
+}
+
+// Load configuration from Docker secrets, skipped when the default directory does not exist
+func (s DockerSecretsSource) Load(structPtr interface{}) error {
+	path := s.Path
+	if path == "" {
+		path = "/run/secrets"
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil
 		}
 	}
+	dirMap, err := readDir(nil, path, true)
+	if err != nil {
+		return err
+	}
+	if err := decryptMap(dirMap); err != nil {
+		return err
+	}
+	return map2struct("docker", "", dirMap, structPtr)
+}
+
+// Load configuration from systemd credentials, skipped when not running with LoadCredential=
+func (s SystemdCredentialsSource) Load(structPtr interface{}) error {
+	path := os.Getenv("CREDENTIALS_DIRECTORY")
+	if path == "" {
+		return nil
+	}
+	dirMap, err := readDir(nil, path, true)
+	if err != nil {
+		return err
+	}
+	if err := decryptMap(dirMap); err != nil {
+		return err
+	}
 	return map2struct("dir", "", dirMap, structPtr)
 }
 
+// Load configuration files of the conf.d directory
+func (s ConfDirSource) Load(structPtr interface{}) error {
+	files, err := ioutil.ReadDir(s.Path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		path := filepath.Join(s.Path, file.Name())
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		switch formatOf(path) {
+		case "":
+			continue
+		case "env":
+			err = EnvFileSource{Prefix: s.Prefix, Path: path}.Load(structPtr)
+		default:
+			err = FileSource{Path: path}.Load(structPtr)
+		}
+		if err != nil {
+			return fileError(nil, path, nil, nil, err)
+		}
+	}
+	return nil
+}
+
 //  Load configuration from the command-line.
 func (s FlagsSource) Load(structPtr interface{}) error {
+	return map2struct("flag", "", parseArgs(os.Args[1:]), structPtr)
+}
+
+// parseArgs reads -name=value and -name value arguments into a map
+func parseArgs(args []string) map[string]string {
 	argsMap := map[string]string{}
 	prev := ""
-	for _, s := range os.Args[1:] {
+	for _, s := range args {
 		if strings.HasPrefix(s, "-") {
 			prev = ""
 			if strings.Contains(s, "=") {
@@ -400,7 +594,72 @@ func (s FlagsSource) Load(structPtr interface{}) error {
 			argsMap[prev] = s
 		}
 	}
-	return map2struct("flag", "", argsMap, structPtr)
+	return argsMap
+}
+
+// readDir reads files of the directory in fsys (the OS file system when nil) into a map (file name=value),
+// ENC[...] values are left to decryptMap
+func readDir(fsys fs.FS, path string, trim bool) (map[string]string, error) {
+	dirMap := map[string]string{}
+	files, err := readDirInfo(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		if !file.IsDir() && file.Size() < maxFileSize {
+			data, err := readRawFS(fsys, filepath.Join(path, file.Name()))
+			if err != nil {
+				continue
+			}
+			value := string(data)
+			if trim {
+				value = trimNewline(value)
+			}
+			dirMap[file.Name()] = value
+		}
+	}
+	return dirMap, nil
+}
+
+// trimNewline removes one trailing line ending ("\n" or "\r\n") of a secret file written by echo or an editor
+func trimNewline(value string) string {
+	if strings.HasSuffix(value, "\n") {
+		value = strings.TrimSuffix(strings.TrimSuffix(value, "\n"), "\r")
+	}
+	return value
+}
+
+// readFile reads the configuration file, a file encrypted as a whole (ENC[...]) is decrypted
+func readFile(path string) ([]byte, error) {
+	return readFileFS(nil, path)
+}
+
+// readRawFile reads the file, "-" is the standard input
+func readRawFile(path string) ([]byte, error) {
+	if path == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	file, err := getFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ioutil.ReadAll(file)
+}
+
+func decryptFile(path string, data []byte) ([]byte, error) {
+	if value := strings.TrimSpace(string(data)); isEncrypted(value) {
+		plain, err := new(keyRing).decrypt(value)
+		if err != nil && path != "" {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		} else if err != nil {
+			return nil, err
+		}
+		return []byte(plain), nil
+	}
+	return data, nil
 }
 
 func getFile(path string) (*os.File, error) {
@@ -414,6 +673,215 @@ func getFile(path string) (*os.File, error) {
 	return os.Open(path)
 }
 
+// readFileVars sets values from files referenced by <NAME>_FILE variables (Docker secrets convention)
+func readFileVars(prefix string, envMap map[string]string, structPtr interface{}) error {
+	structElem := reflect.ValueOf(structPtr).Elem()
+	for i := 0; i < structElem.NumField(); i++ {
+		field := structElem.Type().Field(i)
+		tagVal := strings.TrimSpace(field.Tag.Get("env"))
+		if tagVal == "-" {
+			continue
+		}
+		name, _ := convertName(field.Name, "env", tagVal, prefix)
+		path := strings.TrimSpace(envValue(envMap, name+"_FILE"))
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("%s_FILE: %s: %w", name, path, ErrIsDirectory)
+		}
+		if info.Size() >= maxFileSize {
+			return fmt.Errorf("%s_FILE: %s: %w", name, path, ErrFileTooLarge)
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		value := trimNewline(string(data))
+		if current := envValue(envMap, name); current != "" && current != value {
+			return fmt.Errorf("both %s and %s_FILE are set with different values", name, name)
+		}
+		envMap[name] = value
+	}
+	return nil
+}
+
+// envValue returns the value of the variable, keys are compared upper-cased like setValue does
+func envValue(envMap map[string]string, name string) string {
+	if value, ok := envMap[name]; ok {
+		return value
+	}
+	for key, value := range envMap {
+		if strings.ToUpper(strings.TrimSpace(key)) == name {
+			return value
+		}
+	}
+	return ""
+}
+
+// decodeFormat decodes data of the registered format into a scratch copy of the struct, so the struct
+// is left untouched on errors. Unknown format is detected by content or, failing that, by trying all formats.
+func decodeFormat(format, path string, data []byte, structPtr interface{}) error {
+	if format == "" {
+		format = sniffFormat(data)
+	}
+	if format != "" {
+		return decodeScratch(format, path, data, structPtr)
+	}
+
+	errs := []string{}
+	for _, format := range registeredFormats() {
+		if format.sniffer == nil {
+			continue
+		}
+		err := decodeScratch(format.name, path, data, structPtr)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", format.name, err))
+	}
+	return fmt.Errorf("%w (%s)", ErrUnknownFileType, strings.Join(errs, "; "))
+}
+
+func decodeScratch(format, path string, data []byte, structPtr interface{}) error {
+	fileFormat, ok := lookupFormat(format)
+	if !ok {
+		return fmt.Errorf("%s: %w", format, ErrUnknownFileType)
+	}
+	scratch := snapshot(structPtr)
+	var err error
+	if fileFormat.fileDecoder != nil {
+		err = fileFormat.fileDecoder(path, data, scratch.Addr().Interface())
+	} else {
+		err = fileFormat.decoder(data, scratch.Addr().Interface())
+	}
+	if err != nil {
+		return err
+	}
+	reflect.ValueOf(structPtr).Elem().Set(scratch)
+	return nil
+}
+
+func decodeJSON(data []byte, structPtr interface{}) error {
+	return decodeJSONMapped(data, data, nil, structPtr)
+}
+
+// decodeJSONMapped decodes JSON converted from the source, offsets map data bytes to source offsets
+func decodeJSONMapped(source, data []byte, offsets []int, structPtr interface{}) error {
+	before := snapshot(structPtr)
+	data, decrypted, err := decryptEncoded(data, structPtr, "json", func(data []byte) (interface{}, error) {
+		var doc interface{}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		err := decoder.Decode(&doc)
+		return doc, err
+	}, json.Marshal)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, structPtr); err != nil {
+		if decrypted != nil {
+			// positions of the encoded back document do not match the source
+			return err
+		}
+		return jsonPositionError(source, data, offsets, err)
+	}
+	return decodeValues(before, structPtr, decrypted)
+}
+
+// decodeYAML applies the documents of the YAML stream in order, later documents override earlier ones
+func decodeYAML(data []byte, structPtr interface{}) error {
+	before := snapshot(structPtr)
+	keys, decrypted := new(keyRing), map[string]bool{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		node := new(yaml.Node)
+		if err := decoder.Decode(node); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if err := decryptYAML(keys, node, reflect.TypeOf(structPtr).Elem(), nil, decrypted); err != nil {
+			return err
+		}
+		if err := node.Decode(structPtr); err != nil {
+			return err
+		}
+	}
+	return decodeValues(before, structPtr, decrypted)
+}
+
+func decodeTOML(data []byte, structPtr interface{}) error {
+	before := snapshot(structPtr)
+	data, decrypted, err := decryptEncoded(data, structPtr, "toml", func(data []byte) (interface{}, error) {
+		doc := map[string]interface{}{}
+		_, err := toml.Decode(string(data), &doc)
+		return doc, err
+	}, func(doc interface{}) ([]byte, error) {
+		buf := new(bytes.Buffer)
+		err := toml.NewEncoder(buf).Encode(doc)
+		return buf.Bytes(), err
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := toml.Decode(string(data), structPtr); err != nil {
+		return err
+	}
+	return decodeValues(before, structPtr, decrypted)
+}
+
+// decodeValues decrypts ENC[...] values left in the decoded struct and expands ${...} references,
+// values decrypted by the decoder (paths of documentDecrypted) and here are not expanded
+func decodeValues(before reflect.Value, structPtr interface{}, documentDecrypted map[string]bool) error {
+	decrypted, err := decryptStruct(structPtr)
+	if err != nil {
+		return err
+	}
+	for path := range documentDecrypted {
+		decrypted[path] = true
+	}
+	return interpolateStruct(before, structPtr, decrypted)
+}
+
+func decodeEDN(data []byte, structPtr interface{}) error {
+	before := snapshot(structPtr)
+	data, decrypted, err := decryptEncoded(data, structPtr, "edn", func(data []byte) (interface{}, error) {
+		var doc interface{}
+		err := edn.Unmarshal(data, &doc)
+		return doc, err
+	}, edn.Marshal)
+	if err != nil {
+		return err
+	}
+	if err := edn.Unmarshal(data, structPtr); err != nil {
+		return err
+	}
+	return decodeValues(before, structPtr, decrypted)
+}
+
+func decodeEnv(prefix string, data []byte, structPtr interface{}) error {
+	envMap, err := godotenv.Unmarshal(string(data))
+	if err != nil {
+		return err
+	}
+	return decodeEnvMap(prefix, envMap, structPtr)
+}
+
+func decodeEnvMap(prefix string, envMap map[string]string, structPtr interface{}) error {
+	if err := decryptMap(envMap); err != nil {
+		return err
+	}
+	if err := readFileVars(prefix, envMap, structPtr); err != nil {
+		return err
+	}
+	return map2struct("env", prefix, envMap, structPtr)
+}
+
 func map2struct(tag, prefix string, mp map[string]string, structPtr interface{}) error {
 	for key, value := range mp {
 		key = strings.TrimSpace(key)
@@ -461,6 +929,8 @@ func convertName(name, tag, tagVal, prefix string) (fieldName, separator string)
 		fieldName = strcase.ToScreamingSnake(name)
 	} else if tag == "dir" {
 		fieldName = strcase.ToKebab(name)
+	} else if tag == "docker" {
+		fieldName = strcase.ToSnake(name)
 	} else {
 		fieldName = name
 		for _, acron := range Acronims {
@@ -1,8 +1,12 @@
 package easyconfig
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -306,3 +310,266 @@ func TestFlagsSourceLoader(t *testing.T) {
 		}
 	})
 }
+
+func TestEnvFileVars(t *testing.T) {
+	dir := t.TempDir()
+	secretPath := filepath.Join(dir, "postgres-password")
+	if err := ioutil.WriteFile(secretPath, []byte("password\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("EnvSource.Load", func(t *testing.T) {
+		os.Setenv("FILE_POSTGRES_PASSWORD_FILE", secretPath)
+		defer os.Unsetenv("FILE_POSTGRES_PASSWORD_FILE")
+		config := new(Config)
+		if err := (EnvSource{Prefix: "FILE"}).Load(config); err != nil {
+			t.Errorf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresPassword != "password" {
+			t.Errorf("PostgresPassword = %q, want %q", config.PostgresPassword, "password")
+		}
+	})
+
+	t.Run("EnvFileSource.Load", func(t *testing.T) {
+		envPath := filepath.Join(dir, "config.env")
+		data := "FILE_POSTGRES_PASSWORD=other\nFILE_POSTGRES_PASSWORD_FILE=" + secretPath + "\n"
+		if err := ioutil.WriteFile(envPath, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		config := new(Config)
+		err := (EnvFileSource{Prefix: "FILE", Path: envPath}).Load(config)
+		if err == nil {
+			t.Fatalf("Error = nil, want conflict error")
+		}
+		if strings.Contains(err.Error(), "password") || strings.Contains(err.Error(), "other") {
+			t.Errorf("Error = %s, must not contain values", err.Error())
+		}
+	})
+
+	t.Run("lower-case name", func(t *testing.T) {
+		envPath := filepath.Join(dir, "lower.env")
+		if err := ioutil.WriteFile(envPath, []byte("file_postgres_password_file="+secretPath+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		config := new(Config)
+		if err := (EnvFileSource{Prefix: "FILE", Path: envPath}).Load(config); err != nil {
+			t.Errorf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresPassword != "password" {
+			t.Errorf("PostgresPassword = %q, want %q", config.PostgresPassword, "password")
+		}
+	})
+}
+
+func TestDockerSecretsSourceLoader(t *testing.T) {
+	t.Run("DockerSecretsSource.Load", func(t *testing.T) {
+		dir := t.TempDir()
+		files := map[string]string{
+			"postgres_password": "password\r\n",
+			"postgres_port":     "5432\n",
+		}
+		for name, data := range files {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		config := new(Config)
+		if err := (DockerSecretsSource{dir}).Load(config); err != nil {
+			t.Errorf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresPassword != "password" {
+			t.Errorf("PostgresPassword = %q, want %q", config.PostgresPassword, "password")
+		}
+		if config.PostgresPort != 5432 {
+			t.Errorf("PostgresPort = %d, want %d", config.PostgresPort, 5432)
+		}
+		if err := (DockerSecretsSource{filepath.Join(dir, "missing")}).Load(config); err == nil {
+			t.Errorf("Error = nil, want error for missing explicit path")
+		}
+	})
+}
+
+func TestSystemdCredentialsSourceLoader(t *testing.T) {
+	t.Run("SystemdCredentialsSource.Load", func(t *testing.T) {
+		config := new(Config)
+		os.Unsetenv("CREDENTIALS_DIRECTORY")
+		if err := (SystemdCredentialsSource{}).Load(config); err != nil {
+			t.Errorf("Error = %s, want %s", err.Error(), "nil")
+		}
+
+		dir := t.TempDir()
+		if err := ioutil.WriteFile(filepath.Join(dir, "postgres-password"), []byte("password\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		os.Setenv("CREDENTIALS_DIRECTORY", dir)
+		defer os.Unsetenv("CREDENTIALS_DIRECTORY")
+		if err := (SystemdCredentialsSource{}).Load(config); err != nil {
+			t.Errorf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresPassword != "password" {
+			t.Errorf("PostgresPassword = %q, want %q", config.PostgresPassword, "password")
+		}
+	})
+}
+
+func TestConfDirSourceLoader(t *testing.T) {
+	t.Run("ConfDirSource.Load", func(t *testing.T) {
+		dir := t.TempDir()
+		files := map[string]string{
+			"10-base.yaml":  "postgresUser: postgres\npostgresHost: localhost\ncache:\n  host: cache\n  size: 1\n",
+			"20-cache.json": `{"cache": {"size": 64}}`,
+			"30-host.toml":  "postgresHost = \"db\"\n",
+			"40-port.env":   "APP_POSTGRES_PORT=6432\n",
+			"README.md":     "postgresHost: readme\n",
+		}
+		for name, data := range files {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		config := new(IncludeConfig)
+		if err := (ConfDirSource{Path: dir, Prefix: "APP"}).Load(config); err != nil {
+			t.Errorf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "postgres" {
+			t.Errorf("PostgresUser = %q, want %q", config.PostgresUser, "postgres")
+		}
+		if config.PostgresHost != "db" {
+			t.Errorf("PostgresHost = %q, want %q", config.PostgresHost, "db")
+		}
+		if config.PostgresPort != 6432 {
+			t.Errorf("PostgresPort = %d, want %d", config.PostgresPort, 6432)
+		}
+		if config.Cache.Host != "cache" || config.Cache.Size != 64 {
+			t.Errorf("Cache = %+v, want %s", config.Cache, "{Host:cache Size:64}")
+		}
+		if err := (ConfDirSource{Path: filepath.Join(dir, "missing")}).Load(config); err != nil {
+			t.Errorf("Error = %s, want %s", err.Error(), "nil")
+		}
+	})
+}
+
+func TestProfileSourceLoader(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml":            "postgresUser: postgres\npostgresHost: localhost\n",
+		"config.production.yaml": "postgresHost: db.example.com\n",
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(dir, "config.yaml")
+
+	t.Run("ProfileSource.Load", func(t *testing.T) {
+		os.Setenv("APP_PROFILE", "production")
+		defer os.Unsetenv("APP_PROFILE")
+		config := new(Config)
+		if err := (ProfileSource{Path: path, Prefix: "APP"}).Load(config); err != nil {
+			t.Errorf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "postgres" {
+			t.Errorf("PostgresUser = %q, want %q", config.PostgresUser, "postgres")
+		}
+		if config.PostgresHost != "db.example.com" {
+			t.Errorf("PostgresHost = %q, want %q", config.PostgresHost, "db.example.com")
+		}
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		args := os.Args
+		defer func() { os.Args = args }()
+		os.Args = []string{args[0], "-profile", "staging"}
+		source := ProfileSource{Path: path, Prefix: "APP"}
+		if source.ActiveProfile() != "staging" {
+			t.Errorf("ActiveProfile = %q, want %q", source.ActiveProfile(), "staging")
+		}
+		config := new(Config)
+		if err := source.Load(config); err != nil {
+			t.Errorf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresHost != "localhost" {
+			t.Errorf("PostgresHost = %q, want %q", config.PostgresHost, "localhost")
+		}
+	})
+
+	t.Run("Provenance", func(t *testing.T) {
+		os.Setenv("PROVENANCE_PROFILE", "production")
+		os.Setenv("PROVENANCE_POSTGRES_PORT", "5433")
+		defer os.Unsetenv("PROVENANCE_PROFILE")
+		defer os.Unsetenv("PROVENANCE_POSTGRES_PORT")
+		loader := NewLoader([]Source{ProfileSource{Path: path, Prefix: "PROVENANCE"}, EnvSource{"PROVENANCE"}})
+		provenance, err := loader.Provenance(new(Config))
+		if err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		profile := path + " + " + filepath.Join(dir, "config.production.yaml") + " (profile production)"
+		want := map[string]string{"PostgresUser": profile, "PostgresHost": profile, "PostgresPort": "EnvSource"}
+		for field, source := range want {
+			if provenance[field] != source {
+				t.Errorf("Provenance[%s] = %q, want %q", field, provenance[field], source)
+			}
+		}
+	})
+}
+
+func TestConfigPathSourceLoader(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.yaml")
+	if err := ioutil.WriteFile(path, []byte("postgresUser: postgres\npostgresHost: localhost\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("ConfigPathSource.Load", func(t *testing.T) {
+		os.Setenv("APP_CONFIG", path)
+		os.Setenv("APP_POSTGRES_HOST", "db")
+		defer os.Unsetenv("APP_CONFIG")
+		defer os.Unsetenv("APP_POSTGRES_HOST")
+		config := new(Config)
+		loader := NewLoader([]Source{
+			ConfigPathSource{Prefix: "APP", Default: filepath.Join(dir, "missing.yaml")},
+			EnvSource{"APP"},
+		})
+		if err := loader.Load(config); err != nil {
+			t.Errorf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "postgres" {
+			t.Errorf("PostgresUser = %q, want %q", config.PostgresUser, "postgres")
+		}
+		if config.PostgresHost != "db" {
+			t.Errorf("PostgresHost = %q, want %q", config.PostgresHost, "db")
+		}
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		args := os.Args
+		defer func() { os.Args = args }()
+		os.Args = []string{args[0], "-configFile=" + path}
+		config := &struct {
+			ConfigFile   string
+			PostgresUser string `yaml:"postgresUser"`
+		}{}
+		if err := (ConfigPathSource{Field: "ConfigFile"}).Load(config); err != nil {
+			t.Errorf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "postgres" {
+			t.Errorf("PostgresUser = %q, want %q", config.PostgresUser, "postgres")
+		}
+		if config.ConfigFile != path {
+			t.Errorf("ConfigFile = %q, want %q", config.ConfigFile, path)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if err := (ConfigPathSource{Default: filepath.Join(dir, "missing.yaml")}).Load(new(Config)); err != nil {
+			t.Errorf("Error = %s, want %s", err.Error(), "nil")
+		}
+		os.Setenv("CONFIG", filepath.Join(dir, "missing.yaml"))
+		defer os.Unsetenv("CONFIG")
+		err := (ConfigPathSource{}).Load(new(Config))
+		if !errors.Is(err, os.ErrNotExist) || strings.Count(err.Error(), "missing.yaml") != 1 {
+			t.Errorf("Error = %v, want not exist error with the path once", err)
+		}
+	})
+}
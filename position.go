@@ -0,0 +1,209 @@
+package easyconfig
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hashicorp/hcl/v2"
+	"olympos.io/encoding/edn"
+)
+
+// FileError is the error of the configuration file with the absolute path and, when the decoder
+// reports it, the position of the error and the offending line of the file:
+//
+//	/etc/app/config.yaml:3:15: yaml: unmarshal errors:
+//	  line 3: cannot unmarshal !!str `x` into uint64
+//	    postgresPort: x
+//	                  ^
+type FileError struct {
+	Path   string
+	Line   int
+	Column int
+	Source string
+	Err    error
+}
+
+var (
+	lineRe    = regexp.MustCompile(`\bline (\d+)(?:, column (\d+))?`)
+	quotedRe  = regexp.MustCompile("`([^`]+)`")
+	lastKeyRe = regexp.MustCompile(`last key "([^"]+)"`)
+)
+
+func (e *FileError) Error() string {
+	var b strings.Builder
+	switch {
+	case e.Path != "" && e.Line > 0:
+		fmt.Fprintf(&b, "%s:%d:%d: ", e.Path, e.Line, e.Column)
+	case e.Path != "":
+		fmt.Fprintf(&b, "%s: ", e.Path)
+	case e.Line > 0:
+		fmt.Fprintf(&b, "line %d, column %d: ", e.Line, e.Column)
+	}
+	b.WriteString(e.message())
+	if e.Source != "" {
+		b.WriteString("\n    " + e.Source + "\n    ")
+		// keep tabs of the line, so the caret is under the column
+		for i, ch := range e.Source {
+			if i >= e.Column-1 {
+				break
+			}
+			if ch == '\t' {
+				b.WriteByte('\t')
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteByte('^')
+	}
+	return b.String()
+}
+
+// message of the error, HCL diagnostics without the file name and position already printed
+func (e *FileError) message() string {
+	var diags hcl.Diagnostics
+	if e.Path == "" || !errors.As(e.Err, &diags) || len(diags) == 0 {
+		return e.Err.Error()
+	}
+	messages := []string{}
+	for _, diag := range diags {
+		messages = append(messages, diag.Summary+"; "+diag.Detail)
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// positionError adds line and column of the byte offset to the error
+func positionError(data []byte, offset int, err error) error {
+	line, column := offsetPosition(data, offset)
+	return &FileError{Line: line, Column: column, Err: err}
+}
+
+func offsetPosition(data []byte, offset int) (int, int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1, offset - bytes.LastIndexByte(data[:offset], '\n')
+}
+
+// fileError wraps the decode error of the file with its absolute path (the path in fsys when it is
+// not nil), position and offending line. Values of the line are masked when the struct has secret
+// fields. Errors of included files already wrapped with their path are returned as is.
+func fileError(fsys fs.FS, path string, data []byte, structPtr interface{}, err error) error {
+	var fileErr *FileError
+	if errors.As(err, &fileErr) && fileErr.Path != "" {
+		return err
+	}
+	switch {
+	case baseFS(fsys) != nil:
+		path = fsPath(path)
+	case path == "" || path == "-":
+		// bytes, readers and the standard input
+	default:
+		if abs, absErr := filepath.Abs(path); absErr == nil {
+			path = abs
+		}
+	}
+	e := &FileError{Path: path, Err: err}
+	if positionErr, ok := err.(*FileError); ok {
+		e.Line, e.Column, e.Err = positionErr.Line, positionErr.Column, positionErr.Err
+	} else if !errors.Is(err, ErrUnknownFileType) {
+		e.Line, e.Column = errorPosition(data, err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	if e.Line > 0 && e.Line <= len(lines) {
+		e.Source = strings.TrimRight(lines[e.Line-1], "\r")
+		if e.Column <= 0 {
+			e.Column = guessColumn(e.Source, e.Err.Error())
+		}
+		if strings.TrimSpace(e.Source) == "" {
+			e.Source = ""
+		}
+		if structPtr != nil && hasSecrets(reflect.TypeOf(structPtr)) {
+			e.Source = maskLine(e.Source)
+		}
+	}
+	return e
+}
+
+// maskLine replaces the value part of the line (after the first ":" or "=", the whole line without them)
+// with "*", keeping the length so the caret stays under the column
+func maskLine(line string) string {
+	masked := []byte(line)
+	start := strings.IndexAny(line, ":=") + 1
+	for i := start; i < len(masked); i++ {
+		if masked[i] != ' ' && masked[i] != '\t' {
+			masked[i] = '*'
+		}
+	}
+	return string(masked)
+}
+
+// errorPosition returns line and column of the decoder error, column is 0 when unknown
+func errorPosition(data []byte, err error) (int, int) {
+	var (
+		tomlErr  toml.ParseError
+		ednErr   *edn.SyntaxError
+		xmlErr   *xml.SyntaxError
+		hclDiags hcl.Diagnostics
+	)
+	switch {
+	case errors.As(err, &tomlErr):
+		if tomlErr.Position.Start > 0 {
+			return offsetPosition(data, tomlErr.Position.Start)
+		}
+		return tomlErr.Position.Line, 0
+	case errors.As(err, &ednErr):
+		return offsetPosition(data, int(ednErr.Offset))
+	case errors.As(err, &xmlErr):
+		return xmlErr.Line, 0
+	case errors.As(err, &hclDiags):
+		for _, diag := range hclDiags {
+			if diag.Subject != nil {
+				return diag.Subject.Start.Line, diag.Subject.Start.Column
+			}
+		}
+		return 0, 0
+	}
+
+	// yaml, toml, ini and properties errors: "line 3: ..."
+	match := lineRe.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0, 0
+	}
+	line, _ := strconv.Atoi(match[1])
+	column, _ := strconv.Atoi(match[2])
+	return line, column
+}
+
+// guessColumn returns the column of the value quoted in the message (yaml `value`, toml last key)
+// or of the first character of the line
+func guessColumn(line, message string) int {
+	if match := quotedRe.FindStringSubmatch(message); match != nil {
+		if i := strings.LastIndex(line, match[1]); i >= 0 {
+			return i + 1
+		}
+	}
+	if match := lastKeyRe.FindStringSubmatch(message); match != nil {
+		key := match[1][strings.LastIndex(match[1], ".")+1:]
+		if i := strings.Index(line, key); i >= 0 {
+			return i + 1
+		}
+	}
+	return len(line) - len(strings.TrimLeft(line, " \t")) + 1
+}
@@ -0,0 +1,69 @@
+package easyconfig
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileError(t *testing.T) {
+	tests := []struct {
+		source       fileSource
+		data         string
+		line, column int
+	}{
+		{JSONSource{"config.json"}, "{\n  \"postgresUser\": \"postgres\",\n  \"postgresPort\": \"port\"\n}", 3, 19},
+		{JSONSource{"config.json"}, "{\n  \"postgresUser\": \"postgres\"\n  \"postgresPort\": 5432\n}", 3, 3},
+		{JSON5Source{"config.json5"}, "{\n  // comment\n  postgresPort: 'port',\n}", 3, 17},
+		{YAMLSource{"config.yaml"}, "postgresUser: postgres\npostgresPort: port\n", 2, 15},
+		{YAMLSource{"config.yaml"}, "postgresUser: postgres\n  postgresPort: x: y\n", 2, 3},
+		{TOMLSource{"config.toml"}, "postgresUser = \"postgres\"\npostgresPort = \"port\"\n", 2, 1},
+		{TOMLSource{"config.toml"}, "postgresUser = \"postgres\"\npostgresPort = = 5432\n", 2, 16},
+		{EDNSource{"config.edn"}, "{:postgresUser \"postgres\"\n :postgresPort 5432 \"x\" ::a}", 2, 29},
+		{XMLSource{"config.xml"}, "<config>\n  <name>app</nam>\n</config>", 2, 3},
+		{INISource{"config.ini"}, "[postgres]\nuser = postgres\nport\n", 3, 1},
+		{PropertiesSource{"config.properties"}, "postgres.user=postgres\npostgres.port=\\u00zz\n", 2, 1},
+	}
+	for _, tt := range tests {
+		err := tt.source.decode(nil, []byte(tt.data), new(Config))
+		var fileErr *FileError
+		if !errors.As(err, &fileErr) {
+			t.Errorf("%T: Error = %v, want FileError", tt.source, err)
+			continue
+		}
+		path, _ := filepath.Abs(tt.source.filePath())
+		if fileErr.Path != path || fileErr.Line != tt.line || fileErr.Column != tt.column {
+			t.Errorf("%T: Error = %s, want %s:%d:%d", tt.source, err, path, tt.line, tt.column)
+		}
+		lines := strings.Split(err.Error(), "\n")
+		if lines[len(lines)-2] != "    "+strings.Split(tt.data, "\n")[tt.line-1] || lines[len(lines)-1] != strings.Repeat(" ", tt.column+3)+"^" {
+			t.Errorf("%T: Error = %s, want the line %d with a caret", tt.source, err, tt.line)
+		}
+	}
+}
+
+func TestFileErrorSecret(t *testing.T) {
+	config := &struct {
+		User     string `json:"user" yaml:"user"`
+		Password Secret `json:"pass" yaml:"pass"`
+	}{}
+	tests := []struct {
+		source fileSource
+		data   string
+	}{
+		{JSONSource{"config.json"}, "{\n  \"pass\": \"hunter2\n}"},
+		{YAMLSource{"config.yaml"}, "user: app\npass: hunter2: x\n"},
+	}
+	for _, tt := range tests {
+		err := tt.source.decode(nil, []byte(tt.data), config)
+		var fileErr *FileError
+		if !errors.As(err, &fileErr) || fileErr.Line != 2 {
+			t.Errorf("%T: Error = %v, want FileError at line 2", tt.source, err)
+			continue
+		}
+		if strings.Contains(err.Error(), "hunter2") {
+			t.Errorf("%T: Error = %s, must not contain the secret", tt.source, err)
+		}
+	}
+}
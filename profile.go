@@ -0,0 +1,84 @@
+package easyconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProfileSource loads the base configuration file (any format FileSource recognizes) and then
+// the overlay of the active profile, config.<profile>.<ext> next to it. The profile is
+// Profile, the -profile flag or <PREFIX>_PROFILE environment variable (APP_PROFILE),
+// a missing overlay is skipped. Documents of a multi-document YAML base file with the top-level
+// ProfileKey are applied only for the active profile.
+type ProfileSource struct {
+	Path    string
+	Prefix  string
+	Profile string
+}
+
+// Load the base configuration file and the profile overlay
+func (s ProfileSource) Load(structPtr interface{}) error {
+	profile := s.ActiveProfile()
+	var base Source = FileSource{Path: s.Path}
+	if formatOf(s.Path) == "yaml" {
+		base = YAMLDocumentSource{Path: s.Path, Key: ProfileKey, Value: profile}
+	}
+	if err := base.Load(structPtr); err != nil {
+		return err
+	}
+	if profile == "" {
+		return nil
+	}
+	overlay := s.OverlayPath(profile)
+	if _, err := os.Stat(overlay); os.IsNotExist(err) {
+		return nil
+	}
+	return (FileSource{Path: overlay}).Load(structPtr)
+}
+
+// ActiveProfile returns the profile selected by Profile, the -profile flag or the environment variable
+func (s ProfileSource) ActiveProfile() string {
+	if s.Profile != "" {
+		return s.Profile
+	}
+	args := parseArgs(os.Args[1:])
+	for _, name := range []string{"-profile", "--profile"} {
+		if profile := strings.TrimSpace(args[name]); profile != "" {
+			return profile
+		}
+	}
+	return strings.TrimSpace(os.Getenv(s.envName()))
+}
+
+// OverlayPath returns the path of the profile overlay: config.yaml -> config.<profile>.yaml
+func (s ProfileSource) OverlayPath(profile string) string {
+	ext := filepath.Ext(s.Path)
+	return strings.TrimSuffix(s.Path, ext) + "." + profile + ext
+}
+
+// String describes the source with the active profile, "config.yaml + config.production.yaml (profile production)"
+func (s ProfileSource) String() string {
+	profile := s.ActiveProfile()
+	if profile == "" {
+		return s.Path + " (no profile)"
+	}
+	return fmt.Sprintf("%s + %s (profile %s)", s.Path, s.OverlayPath(profile), profile)
+}
+
+func (s ProfileSource) envName() string {
+	name, _ := convertName("Profile", "env", "", s.Prefix)
+	return name
+}
+
+func (s ProfileSource) help() {
+	fmt.Printf("\nConfiguration profile (-profile or %s):\n\n", s.envName())
+	if profile := s.ActiveProfile(); profile != "" {
+		bold.Printf("    %s", profile)
+		fmt.Printf("\n        Overlay %s on top of %s\n", s.OverlayPath(profile), s.Path)
+	} else {
+		bold.Printf("    none")
+		fmt.Printf("\n        Only %s is loaded\n", s.Path)
+	}
+}
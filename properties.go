@@ -0,0 +1,164 @@
+package easyconfig
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PropertiesSource loads configuration from the given Java .properties file. Dotted keys
+// (postgres.host) map to nested structs or to the field with the same name without dots
+// (PostgresHost), names match the properties tag or the field name ignoring case, "_" and "-".
+// Slice values are separated by commas.
+type PropertiesSource struct {
+	Path string
+}
+
+const ErrPropertiesSyntax strErr = "invalid properties syntax"
+
+func init() {
+	RegisterFormat("properties", []string{".properties"}, decodeProperties, nil)
+}
+
+// Load .properties configuration file
+func (s PropertiesSource) Load(structPtr interface{}) error {
+	data, err := readFile(s.Path)
+	if err != nil {
+		return err
+	}
+	return s.decode(nil, data, structPtr)
+}
+
+func (s PropertiesSource) filePath() string { return s.Path }
+
+func (s PropertiesSource) decode(fsys fs.FS, data []byte, structPtr interface{}) error {
+	return includeFile(fsys, nil, "properties", s.Path, data, structPtr)
+}
+
+func decodeProperties(data []byte, structPtr interface{}) error {
+	properties, err := parseProperties(string(data))
+	if err != nil {
+		return err
+	}
+	before := snapshot(structPtr)
+	structElem := reflect.ValueOf(structPtr).Elem()
+	for _, p := range properties {
+		field, separator, ok := nestedField(structElem, "properties", strings.Split(p[0], "."))
+		if !ok {
+			if field, separator, ok = structField(structElem, "properties", p[0]); !ok {
+				continue
+			}
+		}
+		if field.Kind() == reflect.Slice {
+			if separator == "" {
+				separator = ","
+			}
+			field.Set(reflect.Zero(field.Type()))
+			setSlice(field.Addr().Interface(), p[1], separator)
+			continue
+		}
+		setField(field.Addr().Interface(), p[1])
+	}
+	return decodeValues(before, structPtr, nil)
+}
+
+// parseProperties returns key, value pairs in the order of the file
+func parseProperties(data string) ([][2]string, error) {
+	properties := [][2]string{}
+	lines := strings.Split(strings.TrimPrefix(strings.ReplaceAll(data, "\r\n", "\n"), "\xef\xbb\xbf"), "\n")
+	for n := 0; n < len(lines); n++ {
+		start := n + 1
+		line := strings.TrimLeft(lines[n], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		// a line ending with an odd number of backslashes continues on the next line
+		for trailingBackslashes(line)%2 == 1 {
+			line = line[:len(line)-1]
+			if n+1 < len(lines) {
+				n++
+				line += strings.TrimLeft(lines[n], " \t\f")
+			}
+		}
+
+		key, value, err := splitProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", start, err)
+		}
+		properties = append(properties, [2]string{key, value})
+	}
+	return properties, nil
+}
+
+func trailingBackslashes(line string) int {
+	count := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		count++
+	}
+	return count
+}
+
+// splitProperty splits the line at the first unescaped =, : or whitespace and unescapes key and value
+func splitProperty(line string) (string, string, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	key, err := unescapeProperty(line[:end])
+	if err != nil {
+		return "", "", err
+	}
+	value, err := unescapeProperty(rest)
+	return key, value, err
+}
+
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("%w: malformed \\uxxxx escape", ErrPropertiesSyntax)
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("%w: malformed \\uxxxx escape", ErrPropertiesSyntax)
+			}
+			b.WriteRune(rune(r))
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,69 @@
+package easyconfig
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type (
+	PropertiesConfig struct {
+		AppName  string
+		Postgres struct {
+			User     string
+			Password string
+			Host     string
+			Port     int
+			Hosts    []string
+			DSN      string
+			SSLMode  string `properties:"ssl-mode"`
+		}
+		CacheSize int
+	}
+)
+
+func TestPropertiesSourceLoader(t *testing.T) {
+	t.Run("PropertiesSource.Load", func(t *testing.T) {
+		config := new(PropertiesConfig)
+		if err := (PropertiesSource{"tests/config.properties"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.AppName != "easyconfig" {
+			t.Errorf("AppName = %s, want %s", config.AppName, "easyconfig")
+		}
+		if config.Postgres.User != "postgres" || config.Postgres.Host != "localhost" || config.Postgres.Port != 5432 {
+			t.Errorf("Postgres = %+v, want %s", config.Postgres, "postgres localhost 5432")
+		}
+		if config.Postgres.Password != "pa:ss=word" {
+			t.Errorf("Password = %s, want %s", config.Postgres.Password, "pa:ss=word")
+		}
+		if fmt.Sprint(config.Postgres.Hosts) != "[db1 db2]" {
+			t.Errorf("Hosts = %v, want %s", config.Postgres.Hosts, "[db1 db2]")
+		}
+		if config.Postgres.DSN != "host=localhost dbname=db-name" {
+			t.Errorf("DSN = %s, want %s", config.Postgres.DSN, "host=localhost dbname=db-name")
+		}
+		if config.Postgres.SSLMode != "disable" {
+			t.Errorf("SSLMode = %s, want %s", config.Postgres.SSLMode, "disable")
+		}
+		if config.CacheSize != 64 {
+			t.Errorf("CacheSize = %d, want %d", config.CacheSize, 64)
+		}
+	})
+
+	t.Run("FileSource.Load", func(t *testing.T) {
+		config := new(PropertiesConfig)
+		if err := (FileSource{Path: "tests/config.properties"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.Postgres.User != "postgres" {
+			t.Errorf("User = %s, want %s", config.Postgres.User, "postgres")
+		}
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		if err := decodeProperties([]byte("name=\\u00zz\n"), new(PropertiesConfig)); !errors.Is(err, ErrPropertiesSyntax) {
+			t.Errorf("Error = %v, want %s", err, ErrPropertiesSyntax)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package easyconfig
+
+import (
+	"io"
+	"io/ioutil"
+)
+
+// BytesSource loads configuration from Data. Format (json, yaml, toml, edn, env or a registered
+// format) is detected by content when empty, included files are relative to the working directory.
+type BytesSource struct {
+	Data   []byte
+	Format string
+}
+
+// ReaderSource loads configuration read from Reader (an HTTP body, a pipe),
+// Format is the same as for BytesSource.
+type ReaderSource struct {
+	Reader io.Reader
+	Format string
+}
+
+// StdinSource loads configuration from the standard input. FileSource and other file
+// sources read the standard input for the path "-" too.
+type StdinSource struct {
+	Format string
+}
+
+// Load configuration from bytes
+func (s BytesSource) Load(structPtr interface{}) error {
+	data, err := decryptFile("", s.Data)
+	if err != nil {
+		return err
+	}
+	return FileSource{Format: s.Format}.decode(nil, data, structPtr)
+}
+
+// Load configuration from the reader
+func (s ReaderSource) Load(structPtr interface{}) error {
+	data, err := ioutil.ReadAll(s.Reader)
+	if err != nil {
+		return err
+	}
+	return BytesSource{Data: data, Format: s.Format}.Load(structPtr)
+}
+
+// Load configuration from the standard input
+func (s StdinSource) Load(structPtr interface{}) error {
+	return FileSource{Path: "-", Format: s.Format}.Load(structPtr)
+}
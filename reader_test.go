@@ -0,0 +1,81 @@
+package easyconfig
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReaderSourceLoader(t *testing.T) {
+	t.Run("BytesSource.Load", func(t *testing.T) {
+		config := new(Config)
+		if err := (BytesSource{Data: []byte("postgresUser: postgres\npostgresPort: 5432\n"), Format: "yaml"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "postgres" || config.PostgresPort != 5432 {
+			t.Errorf("Config = %+v, want %s", config, "postgres 5432")
+		}
+
+		if err := (BytesSource{Data: []byte("POSTGRES_HOST=localhost\n"), Format: ".env"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresHost != "localhost" {
+			t.Errorf("PostgresHost = %s, want %s", config.PostgresHost, "localhost")
+		}
+
+		// detected by content
+		if err := (BytesSource{Data: []byte(`{"postgresDBName": "db-name"}`)}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresDBName != "db-name" {
+			t.Errorf("PostgresDBName = %s, want %s", config.PostgresDBName, "db-name")
+		}
+	})
+
+	t.Run("ReaderSource.Load", func(t *testing.T) {
+		config := new(Config)
+		if err := (ReaderSource{Reader: strings.NewReader("postgresHost = \"localhost\"\n"), Format: "toml"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresHost != "localhost" {
+			t.Errorf("PostgresHost = %s, want %s", config.PostgresHost, "localhost")
+		}
+	})
+
+	t.Run("StdinSource.Load", func(t *testing.T) {
+		stdin := os.Stdin
+		defer func() { os.Stdin = stdin }()
+		for _, source := range []Source{StdinSource{}, FileSource{Path: "-"}, YAMLSource{"-"}} {
+			file, err := ioutil.TempFile("", "stdin")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(file.Name())
+			file.WriteString("postgresUser: postgres\n")
+			file.Seek(0, 0)
+			os.Stdin = file
+
+			config := new(Config)
+			if err := source.Load(config); err != nil {
+				t.Fatalf("%T: Error = %s, want %s", source, err.Error(), "nil")
+			}
+			if config.PostgresUser != "postgres" {
+				t.Errorf("%T: PostgresUser = %s, want %s", source, config.PostgresUser, "postgres")
+			}
+			file.Close()
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if err := (BytesSource{Data: []byte("a: 1"), Format: "xyz"}).Load(new(Config)); !errors.Is(err, ErrUnknownFileType) {
+			t.Errorf("Error = %v, want %s", err, ErrUnknownFileType)
+		}
+		err := (BytesSource{Data: []byte("postgresUser: postgres\npostgresPort: port\n"), Format: "yaml"}).Load(new(Config))
+		var fileErr *FileError
+		if !errors.As(err, &fileErr) || !strings.HasPrefix(err.Error(), "line 2, column 15:") {
+			t.Errorf("Error = %v, want %s", err, "line 2, column 15")
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package easyconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SearchSource looks for the config file Name (myapp.yaml) in the ordered locations:
+// Path, $XDG_CONFIG_HOME/<app>, $XDG_CONFIG_DIRS/<app>, ~/.<app>, /etc/<app> and the working
+// directory with its parents up to the repository root. App is Name without the extension by default.
+// The first file found is loaded, with MergeAll all files found are loaded, earlier locations override later ones.
+// A missing Path is an error, other locations are not searched then.
+type SearchSource struct {
+	Name     string
+	App      string
+	Path     string
+	MergeAll bool
+}
+
+const ErrConfigNotFound strErr = "config file not found"
+
+// Load the config file found in the search paths
+func (s SearchSource) Load(structPtr interface{}) error {
+	if s.Path != "" {
+		if info, err := os.Stat(s.Path); err != nil || info.IsDir() {
+			return fmt.Errorf("%s: %w", s.Path, ErrConfigNotFound)
+		}
+	}
+	candidates := s.Candidates()
+	found := []string{}
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			found = append(found, path)
+			if !s.MergeAll {
+				break
+			}
+		}
+	}
+	if len(found) == 0 {
+		return fmt.Errorf("%s: %w, tried: %s", s.Name, ErrConfigNotFound, strings.Join(candidates, ", "))
+	}
+
+	for i := len(found) - 1; i >= 0; i-- {
+		if err := (FileSource{Path: found[i]}).Load(structPtr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Candidates returns the paths where the config file is looked for, in order of priority
+func (s SearchSource) Candidates() []string {
+	app := s.App
+	if app == "" {
+		app = strings.TrimSuffix(s.Name, filepath.Ext(s.Name))
+	}
+	home, _ := os.UserHomeDir()
+
+	dirs := []string{}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" && home != "" {
+		configHome = filepath.Join(home, ".config")
+	}
+	if configHome != "" {
+		dirs = append(dirs, filepath.Join(configHome, app))
+	}
+	configDirs := os.Getenv("XDG_CONFIG_DIRS")
+	if configDirs == "" {
+		configDirs = "/etc/xdg"
+	}
+	for _, dir := range filepath.SplitList(configDirs) {
+		if dir != "" {
+			dirs = append(dirs, filepath.Join(dir, app))
+		}
+	}
+	if home != "" {
+		dirs = append(dirs, filepath.Join(home, "."+app))
+	}
+	dirs = append(dirs, filepath.Join("/etc", app))
+	dirs = append(dirs, parentDirs()...)
+
+	candidates := []string{}
+	if s.Path != "" {
+		candidates = append(candidates, s.Path)
+	}
+	for _, dir := range dirs {
+		candidates = append(candidates, filepath.Join(dir, s.Name))
+	}
+	return candidates
+}
+
+// parentDirs returns the working directory and its parents up to the repository root (with .git),
+// only the working directory outside of a repository
+func parentDirs() []string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	dirs := []string{}
+	for dir := wd; ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dirs
+		}
+		if filepath.Dir(dir) == dir {
+			return []string{wd}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package easyconfig
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSearchSource(t *testing.T) {
+	dir := t.TempDir()
+	for name, value := range map[string]string{
+		"HOME":            filepath.Join(dir, "home"),
+		"XDG_CONFIG_HOME": filepath.Join(dir, "config"),
+		"XDG_CONFIG_DIRS": filepath.Join(dir, "xdg1") + string(os.PathListSeparator) + filepath.Join(dir, "xdg2"),
+	} {
+		if old, ok := os.LookupEnv(name); ok {
+			defer os.Setenv(name, old)
+		} else {
+			defer os.Unsetenv(name)
+		}
+		os.Setenv(name, value)
+	}
+	files := map[string]string{
+		"config/myapp/myapp.yaml": "postgresHost: localhost\n",
+		"xdg2/myapp/myapp.yaml":   "postgresHost: db\npostgresUser: postgres\n",
+		"home/.myapp/myapp.yaml":  "postgresPort: 5432\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("first match", func(t *testing.T) {
+		config := new(Config)
+		if err := (SearchSource{Name: "myapp.yaml"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresHost != "localhost" || config.PostgresUser != "" {
+			t.Errorf("Config = %+v, want %s", config, "localhost only")
+		}
+	})
+
+	t.Run("MergeAll", func(t *testing.T) {
+		config := new(Config)
+		if err := (SearchSource{Name: "myapp.yaml", MergeAll: true}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresHost != "localhost" {
+			t.Errorf("PostgresHost = %q, want %q", config.PostgresHost, "localhost")
+		}
+		if config.PostgresUser != "postgres" {
+			t.Errorf("PostgresUser = %q, want %q", config.PostgresUser, "postgres")
+		}
+		if config.PostgresPort != 5432 {
+			t.Errorf("PostgresPort = %d, want %d", config.PostgresPort, 5432)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		err := (SearchSource{Name: "missing.yaml"}).Load(new(Config))
+		if !errors.Is(err, ErrConfigNotFound) {
+			t.Fatalf("Error = %v, want %s", err, ErrConfigNotFound)
+		}
+		for _, path := range []string{filepath.Join(dir, "xdg1", "missing", "missing.yaml"), "/etc/missing/missing.yaml"} {
+			if !strings.Contains(err.Error(), path) {
+				t.Errorf("Error = %s, want tried path %s", err.Error(), path)
+			}
+		}
+	})
+
+	t.Run("missing Path", func(t *testing.T) {
+		config := new(Config)
+		err := (SearchSource{Name: "myapp.yaml", Path: "./missing.yaml"}).Load(config)
+		if !errors.Is(err, ErrConfigNotFound) || !strings.Contains(err.Error(), "./missing.yaml") {
+			t.Errorf("Error = %v, want ./missing.yaml: %s", err, ErrConfigNotFound)
+		}
+		if config.PostgresHost != "" {
+			t.Errorf("PostgresHost = %q, want %q", config.PostgresHost, "")
+		}
+	})
+	t.Run("invalid file", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.yaml")
+		if err := ioutil.WriteFile(path, []byte("postgresPort: port\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		err := (SearchSource{Name: "myapp.yaml", Path: path}).Load(new(Config))
+		var fileErr *FileError
+		if !errors.As(err, &fileErr) || strings.Count(err.Error(), path) != 1 {
+			t.Errorf("Error = %v, want FileError with the path once", err)
+		}
+	})
+}
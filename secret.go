@@ -0,0 +1,121 @@
+package easyconfig
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// Secret is a string configuration value that is masked when printed.
+// Use Value to get the raw value. Fields tagged `secret:"true"` are treated the same way
+// by Redact, StripSecrets, Help and generators.
+type Secret string
+
+// SecretMask replaces non-empty secret values in printed output
+const SecretMask = "******"
+
+var secretType = reflect.TypeOf(Secret(""))
+
+// String returns the masked value
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return SecretMask
+}
+
+// GoString returns the masked value for the %#v verb
+func (s Secret) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+// Value returns the raw secret value
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// Redact returns a pointer to a copy of the struct with secret values masked,
+// so it can be safely printed, logged or encoded.
+func Redact(structPtr interface{}) interface{} {
+	structElem := copyStruct(structPtr)
+	maskSecrets(structElem)
+	return structElem.Addr().Interface()
+}
+
+// StripSecrets returns a pointer to a copy of the struct with secret fields set to zero values,
+// so encoders (with omitempty) leave them out.
+func StripSecrets(structPtr interface{}) interface{} {
+	structElem := copyStruct(structPtr)
+	clearSecrets(structElem)
+	return structElem.Addr().Interface()
+}
+
+func isSecret(field reflect.StructField) bool {
+	if field.Type == secretType {
+		return true
+	}
+	secret, _ := strconv.ParseBool(field.Tag.Get("secret"))
+	return secret
+}
+
+func copyStruct(structPtr interface{}) reflect.Value {
+	structElem := reflect.ValueOf(structPtr)
+	for structElem.Kind() == reflect.Ptr {
+		structElem = structElem.Elem()
+	}
+	cp := reflect.New(structElem.Type()).Elem()
+	cp.Set(structElem)
+	return cp
+}
+
+func clearSecrets(structElem reflect.Value) {
+	eachSecret(structElem, func(value reflect.Value) {
+		value.Set(reflect.Zero(value.Type()))
+	})
+}
+
+func maskSecrets(structElem reflect.Value) {
+	eachSecret(structElem, func(value reflect.Value) {
+		if value.Kind() == reflect.String && value.Len() > 0 {
+			value.SetString(SecretMask)
+		} else {
+			value.Set(reflect.Zero(value.Type()))
+		}
+	})
+}
+
+// hasSecrets reports whether the struct type or its nested structs have secret fields
+func hasSecrets(t reflect.Type) bool {
+	return hasSecretsSeen(t, map[reflect.Type]bool{})
+}
+
+func hasSecretsSeen(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return false
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath == "" && (isSecret(field) || hasSecretsSeen(field.Type, seen)) {
+			return true
+		}
+	}
+	return false
+}
+
+// eachSecret calls fn for secret fields of the struct and its nested structs
+func eachSecret(structElem reflect.Value, fn func(value reflect.Value)) {
+	for i := 0; i < structElem.NumField(); i++ {
+		field := structElem.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if isSecret(field) {
+			fn(structElem.Field(i))
+		} else if field.Type.Kind() == reflect.Struct {
+			eachSecret(structElem.Field(i), fn)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package easyconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type (
+	SecretConfig struct {
+		PostgresUser     string `json:"postgresUser"`
+		PostgresPassword string `json:"postgresPassword,omitempty" secret:"true"`
+		APIKey           Secret `json:"apiKey,omitempty"`
+	}
+)
+
+func TestSecret(t *testing.T) {
+	config := &SecretConfig{
+		PostgresUser:     "postgres",
+		PostgresPassword: "password",
+		APIKey:           "key",
+	}
+
+	t.Run("Secret.String", func(t *testing.T) {
+		out := fmt.Sprintf("%v %+v %#v", config.APIKey, config, config)
+		if strings.Contains(out, "key\"") || strings.Contains(out, " key") {
+			t.Errorf("Output = %s, want masked APIKey", out)
+		}
+		if config.APIKey.Value() != "key" {
+			t.Errorf("APIKey.Value() = %s, want %s", config.APIKey.Value(), "key")
+		}
+	})
+
+	t.Run("Redact", func(t *testing.T) {
+		out := fmt.Sprintf("%v", Redact(config))
+		if out != "&{postgres ****** ******}" {
+			t.Errorf("Redact = %s, want %s", out, "&{postgres ****** ******}")
+		}
+		if config.PostgresPassword != "password" {
+			t.Errorf("PostgresPassword = %s, want %s", config.PostgresPassword, "password")
+		}
+	})
+
+	t.Run("StripSecrets", func(t *testing.T) {
+		data, err := json.Marshal(StripSecrets(config))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != `{"postgresUser":"postgres"}` {
+			t.Errorf("StripSecrets = %s, want %s", data, `{"postgresUser":"postgres"}`)
+		}
+	})
+}
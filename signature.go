@@ -0,0 +1,165 @@
+package easyconfig
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+type (
+	// SignedSource verifies the detached signature of the file loaded by the wrapped source
+	// (FileSource, JSONSource, YAMLSource, TOMLSource, EDNSource, EnvFileSource or SOPSSource)
+	// before decoding it. PublicKeys are minisign public keys or base64 encoded ed25519 keys,
+	// the signature is read from SignaturePath (<path>.sig by default) in minisign or SignFile format.
+	// Included files must have a valid <path>.sig signature too.
+	SignedSource struct {
+		Source        Source
+		PublicKeys    []string
+		SignaturePath string
+	}
+
+	signatureKey struct {
+		id  []byte
+		key ed25519.PublicKey
+	}
+)
+
+const (
+	ErrNotFileSource     strErr = "source does not read a single file"
+	ErrInvalidPublicKey  strErr = "invalid public key"
+	ErrInvalidSignature  strErr = "invalid signature file"
+	ErrSignatureMismatch strErr = "signature verification failed"
+)
+
+// Load configuration after the signature is verified
+func (s SignedSource) Load(structPtr interface{}) error {
+	src, ok := s.Source.(fileSource)
+	if !ok {
+		return ErrNotFileSource
+	}
+	data, err := readRawFile(src.filePath())
+	if err != nil {
+		return err
+	}
+	sigPath := s.SignaturePath
+	if sigPath == "" {
+		sigPath = src.filePath() + ".sig"
+	}
+	if err := s.verify(src.filePath(), sigPath, data); err != nil {
+		return err
+	}
+	if data, err = decryptFile(src.filePath(), data); err != nil {
+		return err
+	}
+	fsys := verifiedFS{verify: func(path string, data []byte) error {
+		return s.verify(path, path+".sig", data)
+	}}
+	return src.decode(fsys, data, structPtr)
+}
+
+func (s SignedSource) verify(path, sigPath string, data []byte) error {
+	sig, err := readRawFile(sigPath)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]signatureKey, 0, len(s.PublicKeys))
+	for _, publicKey := range s.PublicKeys {
+		key, err := parsePublicKey(publicKey)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+
+	if bytes.HasPrefix(sig, []byte("untrusted comment:")) {
+		err = verifyMinisign(keys, data, sig)
+	} else {
+		err = verifyEd25519(keys, data, sig)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// SignFile writes the detached ed25519 signature of the file to <path>.sig
+func SignFile(path string, privateKey ed25519.PrivateKey) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, data))
+	return ioutil.WriteFile(path+".sig", []byte(sig+"\n"), 0644)
+}
+
+// parsePublicKey parses minisign public key (file content or the key line) or base64 ed25519 key
+func parsePublicKey(publicKey string) (signatureKey, error) {
+	lines := strings.Split(strings.TrimSpace(publicKey), "\n")
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[len(lines)-1]))
+	switch {
+	case err != nil:
+	case len(raw) == ed25519.PublicKeySize:
+		return signatureKey{key: raw}, nil
+	case len(raw) == 42 && string(raw[:2]) == "Ed":
+		return signatureKey{id: raw[2:10], key: raw[10:]}, nil
+	}
+	return signatureKey{}, ErrInvalidPublicKey
+}
+
+func verifyEd25519(keys []signatureKey, data, sig []byte) error {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil || len(raw) != ed25519.SignatureSize {
+		return ErrInvalidSignature
+	}
+	for _, key := range keys {
+		if ed25519.Verify(key.key, data, raw) {
+			return nil
+		}
+	}
+	return ErrSignatureMismatch
+}
+
+// verifyMinisign checks the minisign signature format:
+// untrusted comment, base64(algorithm, key id, signature), trusted comment, base64(global signature)
+func verifyMinisign(keys []signatureKey, data, sig []byte) error {
+	lines := strings.Split(strings.TrimSpace(string(sig)), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return ErrInvalidSignature
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(raw) != 74 {
+		return ErrInvalidSignature
+	}
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return ErrInvalidSignature
+	}
+	trusted := strings.TrimPrefix(strings.TrimRight(lines[2], "\r"), "trusted comment: ")
+
+	message := data
+	switch string(raw[:2]) {
+	case "Ed":
+	case "ED": // prehashed
+		hash := blake2b.Sum512(data)
+		message = hash[:]
+	default:
+		return ErrInvalidSignature
+	}
+	keyID, signature := raw[2:10], raw[10:]
+	for _, key := range keys {
+		if key.id != nil && !bytes.Equal(key.id, keyID) {
+			continue
+		}
+		if ed25519.Verify(key.key, message, signature) &&
+			ed25519.Verify(key.key, append(append([]byte{}, signature...), trusted...), global) {
+			return nil
+		}
+	}
+	return ErrSignatureMismatch
+}
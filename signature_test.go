@@ -0,0 +1,147 @@
+package easyconfig
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+func TestSignedSource(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []string{base64.StdEncoding.EncodeToString(publicKey)}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(path, []byte("postgresUser: postgres\npostgresPort: 5432\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("SignFile", func(t *testing.T) {
+		if err := SignFile(path, privateKey); err != nil {
+			t.Fatal(err)
+		}
+		config := new(Config)
+		if err := (SignedSource{Source: YAMLSource{path}, PublicKeys: keys}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "postgres" {
+			t.Errorf("PostgresUser = %s, want %s", config.PostgresUser, "postgres")
+		}
+		if config.PostgresPort != 5432 {
+			t.Errorf("PostgresPort = %d, want %d", config.PostgresPort, 5432)
+		}
+	})
+
+	t.Run("minisign", func(t *testing.T) {
+		keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+		data, _ := ioutil.ReadFile(path)
+		hash := blake2b.Sum512(data)
+		signature := ed25519.Sign(privateKey, hash[:])
+		trusted := "timestamp:1700000000\tfile:config.yaml"
+		global := ed25519.Sign(privateKey, append(append([]byte{}, signature...), trusted...))
+		sig := "untrusted comment: signature from minisign secret key\n" +
+			base64.StdEncoding.EncodeToString(append(append([]byte("ED"), keyID...), signature...)) + "\n" +
+			"trusted comment: " + trusted + "\n" +
+			base64.StdEncoding.EncodeToString(global) + "\n"
+		sigPath := filepath.Join(dir, "config.yaml.minisig")
+		if err := ioutil.WriteFile(sigPath, []byte(sig), 0600); err != nil {
+			t.Fatal(err)
+		}
+		minisignKey := "untrusted comment: minisign public key\n" +
+			base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), publicKey...))
+
+		config := new(Config)
+		source := SignedSource{Source: FileSource{Path: path}, PublicKeys: []string{minisignKey}, SignaturePath: sigPath}
+		if err := source.Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "postgres" {
+			t.Errorf("PostgresUser = %s, want %s", config.PostgresUser, "postgres")
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		otherKey, _, _ := ed25519.GenerateKey(rand.Reader)
+		source := SignedSource{Source: YAMLSource{path}, PublicKeys: []string{base64.StdEncoding.EncodeToString(otherKey)}}
+		if err := source.Load(new(Config)); !errors.Is(err, ErrSignatureMismatch) {
+			t.Errorf("Error = %v, want %s", err, ErrSignatureMismatch)
+		}
+	})
+
+	t.Run("tampered file", func(t *testing.T) {
+		if err := ioutil.WriteFile(path, []byte("postgresUser: admin\npostgresPort: 5432\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		config := new(Config)
+		if err := (SignedSource{Source: YAMLSource{path}, PublicKeys: keys}).Load(config); !errors.Is(err, ErrSignatureMismatch) {
+			t.Errorf("Error = %v, want %s", err, ErrSignatureMismatch)
+		}
+		if config.PostgresUser != "" {
+			t.Errorf("PostgresUser = %s, want %s", config.PostgresUser, "")
+		}
+	})
+
+	t.Run("not a file source", func(t *testing.T) {
+		if err := (SignedSource{Source: EnvSource{"APP"}}).Load(new(Config)); err != ErrNotFileSource {
+			t.Errorf("Error = %v, want %s", err, ErrNotFileSource)
+		}
+	})
+
+	t.Run("included files", func(t *testing.T) {
+		path := filepath.Join(dir, "main.yaml")
+		evil := filepath.Join(dir, "evil.yaml")
+		tagged := filepath.Join(dir, "tagged.yaml")
+		files := map[string]string{
+			path:   "postgresUser: postgres\ninclude: evil.yaml\n",
+			evil:   "postgresHost: attacker.example\n",
+			tagged: "postgresUser: postgres\npostgresHost: !include evil.yaml\n",
+		}
+		for name, data := range files {
+			if err := ioutil.WriteFile(name, []byte(data), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if name != evil {
+				if err := SignFile(name, privateKey); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		for _, name := range []string{path, tagged} {
+			config := new(Config)
+			if err := (SignedSource{Source: YAMLSource{name}, PublicKeys: keys}).Load(config); err == nil {
+				t.Errorf("%s: Error = nil, want missing signature of %s", name, evil)
+			}
+			if config.PostgresHost != "" {
+				t.Errorf("%s: PostgresHost = %s, want %s", name, config.PostgresHost, "")
+			}
+		}
+
+		if err := SignFile(evil, privateKey); err != nil {
+			t.Fatal(err)
+		}
+		config := new(Config)
+		if err := (SignedSource{Source: YAMLSource{path}, PublicKeys: keys}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresHost != "attacker.example" {
+			t.Errorf("PostgresHost = %s, want %s", config.PostgresHost, "attacker.example")
+		}
+
+		// tampered included file
+		if err := ioutil.WriteFile(evil, []byte("postgresHost: other.example\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := (SignedSource{Source: YAMLSource{path}, PublicKeys: keys}).Load(new(Config)); !errors.Is(err, ErrSignatureMismatch) {
+			t.Errorf("Error = %v, want %s", err, ErrSignatureMismatch)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package easyconfig
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"olympos.io/encoding/edn"
+)
+
+var (
+	commentRe  = regexp.MustCompile(`^\s*(#|//|;|--|%|/\*|<!--)`)
+	modelineRe = regexp.MustCompile(`(?:\b(?:ft|filetype|syntax)=|-\*-\s*(?:mode:\s*)?)([A-Za-z]+)`)
+	tomlRe     = regexp.MustCompile(`^\[\[?\s*[A-Za-z0-9_."' -]+\s*\]\]?\s*(#.*)?$`)
+	envRe      = regexp.MustCompile(`^(export\s+)?[A-Z_][A-Z0-9_]*=`)
+	keyValueRe = regexp.MustCompile(`^[A-Za-z0-9_."-]+\s*=`)
+	yamlRe     = regexp.MustCompile(`^("[^"]*"|'[^']*'|[^\s#"'{[][^:#]*):(\s|$)`)
+)
+
+// sniffFormat detects the format of the content: shebang and modeline hints first,
+// then sniffers of registered formats. Empty string is returned when the format is unclear.
+func sniffFormat(data []byte) string {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if format := formatHint(data); format != "" {
+		return format
+	}
+	for _, format := range registeredFormats() {
+		if format.sniffer != nil && format.sniffer(data) {
+			return format.name
+		}
+	}
+	return ""
+}
+
+func sniffJSON(data []byte) bool {
+	line := firstLine(data)
+	return (strings.HasPrefix(line, "{") || strings.HasPrefix(line, "[")) && json.Valid(bytes.TrimSpace(data))
+}
+
+func sniffEDN(data []byte) bool {
+	var v interface{}
+	return strings.HasPrefix(firstLine(data), "{") && edn.Unmarshal(bytes.TrimSpace(data), &v) == nil
+}
+
+func sniffEnv(data []byte) bool {
+	return envRe.MatchString(firstLine(data))
+}
+
+func sniffTOML(data []byte) bool {
+	line := firstLine(data)
+	return tomlRe.MatchString(line) || (keyValueRe.MatchString(line) && !envRe.MatchString(line))
+}
+
+func sniffYAML(data []byte) bool {
+	line := firstLine(data)
+	return line == "---" || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "%YAML") ||
+		strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "{") || strings.HasPrefix(line, "[") ||
+		yamlRe.MatchString(line)
+}
+
+// firstLine returns the first line that is not empty or a comment
+func firstLine(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), maxFileSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, ";") {
+			return line
+		}
+	}
+	return ""
+}
+
+// formatHint returns the format named in the shebang or in a vim/emacs modeline of the first or last lines,
+// modelines are accepted only on comment lines, so ft= in values (URL queries) is ignored
+func formatHint(data []byte) string {
+	lines := strings.Split(string(data), "\n")
+	hints := []string{}
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "#!") {
+		fields := strings.Fields(strings.TrimPrefix(lines[0], "#!"))
+		if len(fields) > 0 {
+			hints = append(hints, fields[len(fields)-1])
+		}
+	}
+	for i, line := range lines {
+		if (i >= 5 && i < len(lines)-5) || !commentRe.MatchString(line) {
+			continue
+		}
+		for _, match := range modelineRe.FindAllStringSubmatch(line, -1) {
+			hints = append(hints, match[1])
+		}
+	}
+	for _, hint := range hints {
+		if format := formatName(hint); format != "" {
+			return format
+		}
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+package easyconfig
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSniffFormat(t *testing.T) {
+	tests := map[string]string{
+		`{"postgresUser": "postgres"}`:                       "json",
+		"{:postgresUser \"postgres\" :postgresPort 5432}":    "edn",
+		"---\npostgresUser: postgres\n":                      "yaml",
+		"# comment\npostgresUser: postgres\n":                "yaml",
+		"- a1\n- a2\n":                                       "yaml",
+		"[postgres]\nuser = \"postgres\"\n":                  "toml",
+		"postgresUser = \"postgres\"\n":                      "toml",
+		"APP_POSTGRES_USER=postgres\n":                       "env",
+		"export APP_POSTGRES_USER=postgres\n":                "env",
+		"#!/usr/bin/env yaml\npostgresUser = postgres\n":     "yaml",
+		"postgresUser: postgres\n# vim: set ft=json :\n":     "json",
+		"# -*- mode: toml -*-\n{\"postgresUser\": \"pg\"}\n": "toml",
+		"#!/bin/sh\nAPP_POSTGRES_USER=postgres\n":            "env",
+		";; -*- mode: clojure -*-\n{:postgresUser \"pg\"}\n": "edn",
+		"# vim: set ft=yml :\n{\"postgresUser\": \"pg\"}\n":  "yaml",
+		"#!/usr/bin/env dotenv\npostgresUser: postgres\n":    "env",
+		"postgresHost: http://db/?ft=json\n":                 "yaml",
+		"{\"postgresHost\": \"http://db/?syntax=yaml\"}\n":   "json",
+		"just text": "",
+	}
+	for data, want := range tests {
+		if format := sniffFormat([]byte(data)); format != want {
+			t.Errorf("sniffFormat(%q) = %q, want %q", data, format, want)
+		}
+	}
+}
+
+func TestFileSourceFormat(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("sniffing", func(t *testing.T) {
+		config := new(Config)
+		if err := (FileSource{Path: write("config.edn.conf", "{:postgresUser \"postgres\" :postgresPort 5432}")}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "postgres" || config.PostgresPort != 5432 {
+			t.Errorf("Config = %+v, want %s", config, "postgres 5432")
+		}
+	})
+
+	t.Run("Format", func(t *testing.T) {
+		config := new(Config)
+		if err := (FileSource{Path: write("config.txt", "postgresUser: postgres\n"), Format: "yml"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresUser != "postgres" {
+			t.Errorf("PostgresUser = %q, want %q", config.PostgresUser, "postgres")
+		}
+		if err := (FileSource{Path: write("config.ini", "a=b"), Format: "xls"}).Load(config); !errors.Is(err, ErrUnknownFileType) {
+			t.Errorf("Error = %v, want %s", err, ErrUnknownFileType)
+		}
+	})
+
+	t.Run("failed decoding", func(t *testing.T) {
+		config := &Config{PostgresUser: "postgres"}
+		err := (FileSource{Path: write("config.conf", "just text")}).Load(config)
+		if !errors.Is(err, ErrUnknownFileType) || !strings.Contains(err.Error(), "toml:") {
+			t.Errorf("Error = %v, want %s with details", err, ErrUnknownFileType)
+		}
+		err = (FileSource{Path: write("broken.json", `{"postgresUser": "admin", "postgresPort": "x"}`)}).Load(config)
+		if err == nil {
+			t.Errorf("Error = nil, want decoding error")
+		}
+		if config.PostgresUser != "postgres" {
+			t.Errorf("PostgresUser = %q, want %q", config.PostgresUser, "postgres")
+		}
+	})
+}
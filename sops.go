@@ -0,0 +1,552 @@
+package easyconfig
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"golang.org/x/crypto/openpgp"
+	pgparmor "golang.org/x/crypto/openpgp/armor"
+	yaml "gopkg.in/yaml.v3"
+)
+
+type (
+	// SOPSSource loads configuration from the given SOPS encrypted .yaml, .json or .env file.
+	// The data key is decrypted with local age identities (AgeKeyFile, SOPS_AGE_KEY,
+	// SOPS_AGE_KEY_FILE or ~/.config/sops/age/keys.txt) or an unprotected PGP secret key
+	// exported to PGPKeyFile. Cloud KMS keys are not supported.
+	SOPSSource struct {
+		Path       string
+		Prefix     string
+		AgeKeyFile string
+		PGPKeyFile string
+	}
+
+	sopsMetadata struct {
+		AgeKeys                 []sopsKey      `yaml:"age"`
+		PGPKeys                 []sopsKey      `yaml:"pgp"`
+		KeyGroups               []sopsKeyGroup `yaml:"key_groups"`
+		LastModified            string         `yaml:"lastmodified"`
+		MAC                     string         `yaml:"mac"`
+		UnencryptedSuffix       string         `yaml:"unencrypted_suffix"`
+		EncryptedSuffix         string         `yaml:"encrypted_suffix"`
+		UnencryptedRegex        string         `yaml:"unencrypted_regex"`
+		EncryptedRegex          string         `yaml:"encrypted_regex"`
+		UnencryptedCommentRegex string         `yaml:"unencrypted_comment_regex"`
+		EncryptedCommentRegex   string         `yaml:"encrypted_comment_regex"`
+		MACOnlyEncrypted        bool           `yaml:"mac_only_encrypted"`
+	}
+
+	sopsKeyGroup struct {
+		AgeKeys []sopsKey `yaml:"age"`
+		PGPKeys []sopsKey `yaml:"pgp"`
+	}
+
+	sopsKey struct {
+		Recipient   string `yaml:"recipient"`
+		Fingerprint string `yaml:"fp"`
+		Enc         string `yaml:"enc"`
+	}
+
+	// sopsMap keeps the order of keys required for the MAC
+	sopsMap []sopsItem
+
+	sopsItem struct {
+		Key   string
+		Value interface{}
+	}
+
+	sopsTree struct {
+		metadata *sopsMetadata
+		key      []byte
+	}
+)
+
+const (
+	ErrSOPSMetadata   strErr = "sops metadata not found"
+	ErrSOPSDataKey    strErr = "unable to decrypt sops data key with local age or PGP keys"
+	ErrSOPSKeyGroups  strErr = "sops key groups with shamir threshold are not supported"
+	ErrSOPSMACInvalid strErr = "sops MAC mismatch, file was modified"
+	ErrSOPSKeyIndex   strErr = "sops key index out of range"
+
+	sopsDefaultUnencryptedSuffix = "_unencrypted"
+	sopsMaxKeyIndex              = 64
+)
+
+// sopsMACOnlyEncryptedInit is written to the MAC hash first when mac_only_encrypted is set
+var sopsMACOnlyEncryptedInit = []byte{0x8a, 0x3f, 0xd2, 0xad, 0x54, 0xce, 0x66, 0x52, 0x7b, 0x10, 0x34, 0xf3, 0xd1, 0x47, 0xbe, 0xb, 0xb, 0x97, 0x5b, 0x3b, 0xf4, 0x4f, 0x72, 0xc6, 0xfd, 0xad, 0xec, 0x81, 0x76, 0xf2, 0x7d, 0x69}
+
+var (
+	sopsValueRe   = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.+),iv:(.+),tag:(.+),type:(.+)\]`)
+	sopsEnvKeysRe = regexp.MustCompile(`^(?:key_groups__list_(\d+)__map_)?(age|pgp)__list_(\d+)__map_(\w+)$`)
+)
+
+// Load SOPS encrypted configuration file
+func (s SOPSSource) Load(structPtr interface{}) error {
+	data, err := readFile(s.Path)
+	if err != nil {
+		return err
+	}
+	return s.decode(nil, data, structPtr)
+}
+
+func (s SOPSSource) filePath() string { return s.Path }
+
+func (s SOPSSource) decode(fsys fs.FS, data []byte, structPtr interface{}) error {
+	if filepath.Ext(s.Path) == ".env" {
+		items, metadata, err := parseSOPSEnv(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.Path, err)
+		}
+		tree, err := s.tree(metadata)
+		if err != nil {
+			return err
+		}
+		if err := tree.decrypt(items); err != nil {
+			return fmt.Errorf("%s: %w", s.Path, err)
+		}
+		envMap := map[string]string{}
+		for _, item := range items {
+			envMap[item.Key] = fmt.Sprint(item.Value)
+		}
+		return decodeEnvMap(s.Prefix, envMap, structPtr)
+	}
+
+	// JSON is valid YAML, the node tree keeps the order of keys required for the MAC
+	node := new(yaml.Node)
+	if err := yaml.Unmarshal(data, node); err != nil {
+		return fileError(fsys, s.Path, data, structPtr, err)
+	}
+	metadata := new(sopsMetadata)
+	if len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "sops" {
+				if err := node.Content[i+1].Decode(metadata); err != nil {
+					return fileError(fsys, s.Path, data, structPtr, err)
+				}
+				node.Content = append(node.Content[:i], node.Content[i+2:]...)
+				break
+			}
+		}
+	}
+	value, err := sopsNodeValue(node)
+	if err != nil {
+		return fileError(fsys, s.Path, data, structPtr, err)
+	}
+	doc, _ := value.(sopsMap)
+	tree, err := s.tree(metadata)
+	if err != nil {
+		return err
+	}
+	if err := tree.decrypt(doc); err != nil {
+		return fmt.Errorf("%s: %w", s.Path, err)
+	}
+
+	if filepath.Ext(s.Path) == ".json" {
+		if data, err = json.Marshal(sopsPlainValue(doc)); err != nil {
+			return err
+		}
+		return decodeJSON(data, structPtr)
+	}
+	if data, err = yaml.Marshal(sopsPlainValue(doc)); err != nil {
+		return err
+	}
+	return decodeYAML(data, structPtr)
+}
+
+// tree decrypts the data key of the sops file
+func (s SOPSSource) tree(metadata *sopsMetadata) (*sopsTree, error) {
+	if metadata.MAC == "" || metadata.LastModified == "" {
+		return nil, fmt.Errorf("%s: %w", s.Path, ErrSOPSMetadata)
+	}
+	ageKeys, pgpKeys := metadata.AgeKeys, metadata.PGPKeys
+	if len(metadata.KeyGroups) > 1 {
+		return nil, fmt.Errorf("%s: %w", s.Path, ErrSOPSKeyGroups)
+	}
+	for _, group := range metadata.KeyGroups {
+		ageKeys = append(ageKeys, group.AgeKeys...)
+		pgpKeys = append(pgpKeys, group.PGPKeys...)
+	}
+
+	if len(ageKeys) > 0 {
+		identities, err := s.ageIdentities()
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range ageKeys {
+			if len(identities) == 0 {
+				break
+			}
+			r, err := age.Decrypt(armor.NewReader(strings.NewReader(key.Enc)), identities...)
+			if err != nil {
+				continue
+			}
+			if dataKey, err := ioutil.ReadAll(r); err == nil {
+				return &sopsTree{metadata: metadata, key: dataKey}, nil
+			}
+		}
+	}
+
+	if len(pgpKeys) > 0 && s.PGPKeyFile != "" {
+		keyRing, err := readPGPKeyRing(s.PGPKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range pgpKeys {
+			block, err := pgparmor.Decode(strings.NewReader(key.Enc))
+			if err != nil {
+				continue
+			}
+			md, err := openpgp.ReadMessage(block.Body, keyRing, nil, nil)
+			if err != nil {
+				continue
+			}
+			if dataKey, err := ioutil.ReadAll(md.UnverifiedBody); err == nil {
+				return &sopsTree{metadata: metadata, key: dataKey}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", s.Path, ErrSOPSDataKey)
+}
+
+func (s SOPSSource) ageIdentities() ([]age.Identity, error) {
+	keys := os.Getenv("SOPS_AGE_KEY")
+	paths := []string{s.AgeKeyFile, os.Getenv("SOPS_AGE_KEY_FILE")}
+	if s.AgeKeyFile == "" && os.Getenv("SOPS_AGE_KEY_FILE") == "" {
+		if dir, err := os.UserConfigDir(); err == nil {
+			paths = append(paths, filepath.Join(dir, "sops", "age", "keys.txt"))
+		}
+	}
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		data, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) && path != s.AgeKeyFile {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys += "\n" + string(data)
+	}
+	if strings.TrimSpace(keys) == "" {
+		return nil, nil
+	}
+	return age.ParseIdentities(strings.NewReader(keys))
+}
+
+func readPGPKeyRing(path string) (openpgp.EntityList, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if keyRing, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data)); err == nil {
+		return keyRing, nil
+	}
+	return openpgp.ReadKeyRing(bytes.NewReader(data))
+}
+
+// decrypt replaces encrypted values of the document and verifies the MAC
+func (t *sopsTree) decrypt(doc sopsMap) error {
+	hash := sha512.New()
+	if t.metadata.MACOnlyEncrypted {
+		hash.Write(sopsMACOnlyEncryptedInit)
+	}
+	var walk func(value interface{}, path []string) (interface{}, error)
+	walk = func(value interface{}, path []string) (interface{}, error) {
+		switch v := value.(type) {
+		case sopsMap:
+			for i, item := range v {
+				decrypted, err := walk(item.Value, append(path[:len(path):len(path)], item.Key))
+				if err != nil {
+					return nil, err
+				}
+				v[i].Value = decrypted
+			}
+			return v, nil
+		case []interface{}:
+			for i, item := range v {
+				decrypted, err := walk(item, path)
+				if err != nil {
+					return nil, err
+				}
+				v[i] = decrypted
+			}
+			return v, nil
+		case nil:
+			return nil, nil
+		}
+
+		encrypted := t.shouldBeEncrypted(path, value)
+		if encrypted {
+			str, _ := value.(string)
+			decrypted, err := t.decryptValue(str, strings.Join(path, ":")+":")
+			if err == nil {
+				value, err = sopsTyped(decrypted, sopsValueRe.FindStringSubmatch(str))
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", strings.Join(path, "."), err)
+			}
+		}
+		if !t.metadata.MACOnlyEncrypted || encrypted {
+			hash.Write(sopsBytes(value))
+		}
+		return value, nil
+	}
+	if _, err := walk(doc, nil); err != nil {
+		return err
+	}
+
+	mac, err := t.decryptValue(t.metadata.MAC, t.metadata.LastModified)
+	if err != nil {
+		return fmt.Errorf("mac: %w", err)
+	}
+	if mac != fmt.Sprintf("%X", hash.Sum(nil)) {
+		return ErrSOPSMACInvalid
+	}
+	return nil
+}
+
+func (t *sopsTree) shouldBeEncrypted(path []string, value interface{}) bool {
+	m := t.metadata
+	matchAny := func(match func(string) bool) bool {
+		for _, p := range path {
+			if match(p) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case m.EncryptedSuffix != "":
+		return matchAny(func(p string) bool { return strings.HasSuffix(p, m.EncryptedSuffix) })
+	case m.EncryptedRegex != "":
+		re, err := regexp.Compile(m.EncryptedRegex)
+		return err != nil || matchAny(re.MatchString)
+	case m.UnencryptedRegex != "":
+		re, err := regexp.Compile(m.UnencryptedRegex)
+		return err != nil || !matchAny(re.MatchString)
+	case m.UnencryptedCommentRegex != "" || m.EncryptedCommentRegex != "":
+		// comments are not kept, rely on the value format
+		str, ok := value.(string)
+		return ok && sopsValueRe.MatchString(str)
+	}
+	suffix := m.UnencryptedSuffix
+	if suffix == "" {
+		suffix = sopsDefaultUnencryptedSuffix
+	}
+	return !matchAny(func(p string) bool { return strings.HasSuffix(p, suffix) })
+}
+
+func (t *sopsTree) decryptValue(value, additionalData string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	matches := sopsValueRe.FindStringSubmatch(value)
+	if matches == nil {
+		return "", ErrInvalidEncrypted
+	}
+	var parts [3][]byte
+	for i := range parts {
+		part, err := base64.StdEncoding.DecodeString(matches[i+1])
+		if err != nil {
+			return "", ErrInvalidEncrypted
+		}
+		parts[i] = part
+	}
+	block, err := aes.NewCipher(t.key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(parts[1]))
+	if err != nil {
+		return "", err
+	}
+	plain, err := gcm.Open(nil, parts[1], append(parts[0], parts[2]...), []byte(additionalData))
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(plain), nil
+}
+
+// sopsTyped converts the decrypted value to the type stored in ENC[...,type:...]
+func sopsTyped(value string, matches []string) (interface{}, error) {
+	if matches == nil {
+		return value, nil
+	}
+	switch matches[4] {
+	case "int":
+		return strconv.Atoi(value)
+	case "float":
+		return strconv.ParseFloat(value, 64)
+	case "bool":
+		return strconv.ParseBool(value)
+	}
+	return value, nil
+}
+
+// sopsBytes returns the representation of the value used by sops for the MAC
+func sopsBytes(value interface{}) []byte {
+	switch v := value.(type) {
+	case string:
+		return []byte(v)
+	case bool:
+		if v {
+			return []byte("True")
+		}
+		return []byte("False")
+	case float64:
+		return []byte(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		return []byte(fmt.Sprint(v))
+	}
+}
+
+// sopsNodeValue converts the YAML node to sopsMap, []interface{} and scalar values
+func sopsNodeValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return sopsMap{}, nil
+		}
+		return sopsNodeValue(node.Content[0])
+	case yaml.AliasNode:
+		return sopsNodeValue(node.Alias)
+	case yaml.MappingNode:
+		mp := make(sopsMap, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := sopsNodeValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			mp = append(mp, sopsItem{Key: node.Content[i].Value, Value: value})
+		}
+		return mp, nil
+	case yaml.SequenceNode:
+		list := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			value, err := sopsNodeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, nil
+	}
+	var value interface{}
+	err := node.Decode(&value)
+	return value, err
+}
+
+// sopsPlainValue converts the decrypted tree to values json.Marshal and yaml.Marshal can encode
+func sopsPlainValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case sopsMap:
+		mp := make(map[string]interface{}, len(v))
+		for _, item := range v {
+			mp[item.Key] = sopsPlainValue(item.Value)
+		}
+		return mp
+	case []interface{}:
+		for i, item := range v {
+			v[i] = sopsPlainValue(item)
+		}
+		return v
+	}
+	return value
+}
+
+// parseSOPSEnv parses the sops dotenv format keeping the order of values
+func parseSOPSEnv(data []byte) (sopsMap, *sopsMetadata, error) {
+	items := sopsMap{}
+	metadata := new(sopsMetadata)
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		p := strings.SplitN(string(line), "=", 2)
+		if len(p) != 2 {
+			return nil, nil, fmt.Errorf("invalid dotenv line: %s", p[0])
+		}
+		key, value := p[0], strings.Replace(p[1], "\\n", "\n", -1)
+		if !strings.HasPrefix(key, "sops_") {
+			items = append(items, sopsItem{Key: key, Value: value})
+			continue
+		}
+
+		key = strings.TrimPrefix(key, "sops_")
+		switch key {
+		case "lastmodified":
+			metadata.LastModified = value
+		case "mac":
+			metadata.MAC = value
+		case "unencrypted_suffix":
+			metadata.UnencryptedSuffix = value
+		case "encrypted_suffix":
+			metadata.EncryptedSuffix = value
+		case "unencrypted_regex":
+			metadata.UnencryptedRegex = value
+		case "encrypted_regex":
+			metadata.EncryptedRegex = value
+		case "unencrypted_comment_regex":
+			metadata.UnencryptedCommentRegex = value
+		case "encrypted_comment_regex":
+			metadata.EncryptedCommentRegex = value
+		case "mac_only_encrypted":
+			metadata.MACOnlyEncrypted, _ = strconv.ParseBool(value)
+		default:
+			m := sopsEnvKeysRe.FindStringSubmatch(key)
+			if m == nil {
+				continue
+			}
+			ageKeys, pgpKeys := &metadata.AgeKeys, &metadata.PGPKeys
+			if m[1] != "" {
+				group, err := strconv.Atoi(m[1])
+				if err != nil || group >= sopsMaxKeyIndex {
+					return nil, nil, fmt.Errorf("%s: %w", p[0], ErrSOPSKeyIndex)
+				}
+				for len(metadata.KeyGroups) <= group {
+					metadata.KeyGroups = append(metadata.KeyGroups, sopsKeyGroup{})
+				}
+				ageKeys, pgpKeys = &metadata.KeyGroups[group].AgeKeys, &metadata.KeyGroups[group].PGPKeys
+			}
+			keys := ageKeys
+			if m[2] == "pgp" {
+				keys = pgpKeys
+			}
+			index, err := strconv.Atoi(m[3])
+			if err != nil || index >= sopsMaxKeyIndex {
+				return nil, nil, fmt.Errorf("%s: %w", p[0], ErrSOPSKeyIndex)
+			}
+			for len(*keys) <= index {
+				*keys = append(*keys, sopsKey{})
+			}
+			switch m[4] {
+			case "recipient":
+				(*keys)[index].Recipient = value
+			case "fp":
+				(*keys)[index].Fingerprint = value
+			case "enc":
+				(*keys)[index].Enc = value
+			}
+		}
+	}
+	return items, metadata, nil
+}
@@ -0,0 +1,82 @@
+package easyconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSOPSSourceLoader(t *testing.T) {
+	os.Unsetenv("SOPS_AGE_KEY")
+	os.Unsetenv("SOPS_AGE_KEY_FILE")
+
+	for _, src := range []SOPSSource{
+		{Path: "tests/sops/config.yaml", AgeKeyFile: "tests/sops/age.key"},
+		{Path: "tests/sops/config.json", AgeKeyFile: "tests/sops/age.key"},
+		{Path: "tests/sops/config.env", Prefix: "APP", AgeKeyFile: "tests/sops/age.key"},
+		{Path: "tests/sops/config.yaml", PGPKeyFile: "tests/sops/pgp.asc"},
+	} {
+		t.Run("SOPSSource.Load "+src.Path, func(t *testing.T) {
+			config := new(Config)
+			if err := src.Load(config); err != nil {
+				t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+			}
+			if config.PostgresUser != "postgres" {
+				t.Errorf("PostgresUser = %s, want %s", config.PostgresUser, "postgres")
+			}
+			if config.PostgresPassword != "password" {
+				t.Errorf("PostgresPassword = %s, want %s", config.PostgresPassword, "password")
+			}
+			if config.PostgresPort != 5432 {
+				t.Errorf("PostgresPort = %d, want %d", config.PostgresPort, 5432)
+			}
+			if fmt.Sprintf("%v", config.Slice) != "[a1 a2 a3 a4 a5]" {
+				t.Errorf("Slice = %v, want %s", config.Slice, "[a1 a2 a3 a4 a5]")
+			}
+		})
+	}
+
+	t.Run("SOPSSource.Load without keys", func(t *testing.T) {
+		if err := (SOPSSource{Path: "tests/sops/config.yaml", AgeKeyFile: os.DevNull}).Load(new(Config)); err == nil {
+			t.Errorf("Error = nil, want data key error")
+		}
+	})
+
+	t.Run("SOPSSource.Load modified file", func(t *testing.T) {
+		data, err := ioutil.ReadFile("tests/sops/config.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// swap two encrypted list items, each of them is still decryptable
+		lines := strings.Split(string(data), "\n")
+		for i, line := range lines {
+			if strings.HasPrefix(line, "slice:") {
+				lines[i+1], lines[i+2] = lines[i+2], lines[i+1]
+				break
+			}
+		}
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")), 0600); err != nil {
+			t.Fatal(err)
+		}
+		err = (SOPSSource{Path: path, AgeKeyFile: "tests/sops/age.key"}).Load(new(Config))
+		if !errors.Is(err, ErrSOPSMACInvalid) || !strings.HasPrefix(err.Error(), path+": ") {
+			t.Errorf("Error = %v, want %s: %s", err, path, ErrSOPSMACInvalid)
+		}
+	})
+
+	t.Run("SOPSSource.Load key index", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.env")
+		data := "APP_POSTGRES_USER=ENC[AES256_GCM,data:AA==,iv:AA==,tag:AA==,type:str]\nsops_age__list_999999999__map_enc=key\n"
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := (SOPSSource{Path: path, AgeKeyFile: os.DevNull}).Load(new(Config)); !errors.Is(err, ErrSOPSKeyIndex) {
+			t.Errorf("Error = %v, want %s", err, ErrSOPSKeyIndex)
+		}
+	})
+}
@@ -0,0 +1,20 @@
+# application settings
+name = "easyconfig"
+home = env.APP_TEST_HCL_HOME
+tags = ["a1", "a2"]
+
+postgres {
+  user     = "postgres"
+  host     = "localhost"
+  port     = 5432
+  ssl_mode = "disable"
+}
+
+listener "http" {
+  port = 8080
+}
+
+listener "https" {
+  port = 8443
+  tls  = true
+}
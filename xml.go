@@ -0,0 +1,87 @@
+package easyconfig
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/fs"
+	"reflect"
+	"strings"
+)
+
+// XMLSource loads configuration from the given .xml file. Elements and attributes are bound
+// by encoding/xml struct tags (`xml:"host"`, `xml:"port,attr"`, `xml:"tags>tag"`), nested
+// elements are decoded into nested structs, repeated elements into slices.
+type XMLSource struct {
+	Path string
+}
+
+func init() {
+	RegisterFormat("xml", []string{".xml"}, decodeXML, sniffXML)
+}
+
+// Load XML configuration file
+func (s XMLSource) Load(structPtr interface{}) error {
+	data, err := readFile(s.Path)
+	if err != nil {
+		return err
+	}
+	return s.decode(nil, data, structPtr)
+}
+
+func (s XMLSource) filePath() string { return s.Path }
+
+func (s XMLSource) decode(fsys fs.FS, data []byte, structPtr interface{}) error {
+	return includeFile(fsys, nil, "xml", s.Path, data, structPtr)
+}
+
+// sniffXML detects a file starting with an XML declaration or an element
+func sniffXML(data []byte) bool {
+	if !strings.HasPrefix(firstLine(data), "<") {
+		return false
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if _, ok := token.(xml.StartElement); ok {
+			return true
+		}
+	}
+}
+
+func decodeXML(data []byte, structPtr interface{}) error {
+	// encoding/xml appends repeated elements to slices, so slices of the file are decoded
+	// separately and replace values of the previous source
+	fresh := reflect.New(reflect.TypeOf(structPtr).Elem())
+	if err := xml.Unmarshal(data, fresh.Interface()); err != nil {
+		return err
+	}
+	before := snapshot(structPtr)
+	if err := xml.Unmarshal(data, structPtr); err != nil {
+		return err
+	}
+	replaceSlices(reflect.ValueOf(structPtr).Elem(), fresh.Elem())
+	return decodeValues(before, structPtr, nil)
+}
+
+// replaceSlices sets non-empty slices of src to dst, nested structs are walked
+func replaceSlices(dst, src reflect.Value) {
+	switch dst.Kind() {
+	case reflect.Ptr:
+		if !dst.IsNil() && !src.IsNil() {
+			replaceSlices(dst.Elem(), src.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < dst.NumField(); i++ {
+			if dst.Type().Field(i).PkgPath == "" {
+				replaceSlices(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if src.Len() > 0 {
+			dst.Set(src)
+		}
+	}
+}
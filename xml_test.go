@@ -0,0 +1,77 @@
+package easyconfig
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type (
+	XMLConfig struct {
+		XMLName  xml.Name `xml:"config"`
+		Name     string   `xml:"name,attr"`
+		Tags     []string `xml:"tags>tag"`
+		Postgres struct {
+			User    string `xml:"user"`
+			Host    string `xml:"host"`
+			Port    int    `xml:"port,attr"`
+			SSLMode string `xml:"ssl-mode"`
+		} `xml:"postgres"`
+		Listeners []struct {
+			Name string `xml:"name,attr"`
+			Port int    `xml:"port"`
+		} `xml:"listener"`
+	}
+)
+
+func TestXMLSourceLoader(t *testing.T) {
+	t.Run("XMLSource.Load", func(t *testing.T) {
+		config := new(XMLConfig)
+		if err := (XMLSource{"tests/config.xml"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.Name != "easyconfig" || strings.Join(config.Tags, ",") != "a1,a2" {
+			t.Errorf("Config = %+v, want %s", config, "easyconfig [a1 a2]")
+		}
+		if config.Postgres.User != "postgres" || config.Postgres.Port != 5432 || config.Postgres.SSLMode != "disable" {
+			t.Errorf("Postgres = %+v, want %s", config.Postgres, "postgres 5432 disable")
+		}
+		if len(config.Listeners) != 2 || config.Listeners[0].Name != "http" || config.Listeners[1].Port != 8443 {
+			t.Errorf("Listeners = %+v, want %s", config.Listeners, "http 8080, https 8443")
+		}
+
+		// slices of the previous source are replaced
+		if err := (XMLSource{"tests/config.xml"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if len(config.Tags) != 2 || len(config.Listeners) != 2 {
+			t.Errorf("Tags = %v, Listeners = %+v, want 2 items", config.Tags, config.Listeners)
+		}
+	})
+
+	t.Run("FileSource.Load", func(t *testing.T) {
+		config := new(XMLConfig)
+		if err := (FileSource{Path: "tests/config.xml"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.Postgres.Host != "localhost" {
+			t.Errorf("Host = %s, want %s", config.Postgres.Host, "localhost")
+		}
+		if format := sniffFormat([]byte("<config>\n  <name>app</name>\n</config>\n")); format != "xml" {
+			t.Errorf("sniffFormat = %s, want %s", format, "xml")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		config := &XMLConfig{Name: "before"}
+		err := (XMLSource{"tests/config.xml"}).decode(nil, []byte("<config name=\"after\">\n  <postgres>\n</config>\n"), config)
+		var syntaxErr *xml.SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Line != 3 {
+			t.Errorf("Error = %v, want syntax error on line 3", err)
+		}
+		if config.Name != "before" {
+			t.Errorf("Name = %s, want %s", config.Name, "before")
+		}
+	})
+}
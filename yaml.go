@@ -0,0 +1,165 @@
+package easyconfig
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"reflect"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// ProfileKey is the top-level key selecting documents of multi-document YAML files loaded by
+// ProfileSource, e.g. "profile: prod"
+var ProfileKey = "profile"
+
+// YAMLDocumentSource loads the documents of a multi-document YAML file selected by the
+// discriminator key: documents without the top-level Key and documents where Key is Value
+// (or a list containing Value) are applied in order, later documents override earlier ones.
+//
+//	YAMLDocumentSource{Path: "config.yaml", Key: "profile", Value: "prod"}
+type YAMLDocumentSource struct {
+	Path  string
+	Key   string
+	Value string
+}
+
+// Load the selected documents of the YAML configuration file
+func (s YAMLDocumentSource) Load(structPtr interface{}) error {
+	data, err := readFile(s.Path)
+	if err != nil {
+		return err
+	}
+	return s.decode(nil, data, structPtr)
+}
+
+func (s YAMLDocumentSource) filePath() string { return s.Path }
+
+func (s YAMLDocumentSource) decode(fsys fs.FS, data []byte, structPtr interface{}) error {
+	data, err := selectYAMLDocuments(data, s.Key, s.Value)
+	if err != nil {
+		return err
+	}
+	return includeFile(fsys, nil, "yaml", s.Path, data, structPtr)
+}
+
+// selectYAMLDocuments blanks the documents not matching key and value, so line numbers of
+// the remaining documents are kept for error messages
+func selectYAMLDocuments(data []byte, key, value string) ([]byte, error) {
+	if key == "" {
+		return data, nil
+	}
+	docs, err := yamlDocuments(data)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(data), "\n")
+	for i, doc := range docs {
+		if yamlDocumentMatches(doc, key, value) {
+			continue
+		}
+		start, end := 1, len(lines)+1
+		if i > 0 {
+			start = doc.Line
+		}
+		if i+1 < len(docs) {
+			end = docs[i+1].Line
+		}
+		for n := start; n < end && n <= len(lines); n++ {
+			lines[n-1] = ""
+		}
+		if i > 0 && start <= len(lines) {
+			lines[start-1] = "---"
+		}
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// yamlDocuments parses the documents of the YAML stream
+func yamlDocuments(data []byte) ([]*yaml.Node, error) {
+	docs := []*yaml.Node{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		doc := new(yaml.Node)
+		if err := decoder.Decode(doc); err == io.EOF {
+			return docs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// yamlDocumentMatches reports whether the document has no top-level key or its value matches
+func yamlDocumentMatches(doc *yaml.Node, key, value string) bool {
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return true
+	}
+	mapping := doc.Content[0]
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+		node := mapping.Content[i+1]
+		if node.Kind == yaml.SequenceNode {
+			for _, item := range node.Content {
+				if item.Value == value {
+					return true
+				}
+			}
+			return false
+		}
+		return node.Value == value
+	}
+	return true
+}
+
+// marshalYAML encodes the value with the 2-space indentation of Kubernetes manifests and examples
+func marshalYAML(value interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decryptYAML decrypts ENC[...] scalars of the node before it is bound to the struct, values of numeric
+// and bool fields are resolved to their type. Paths of the decrypted fields are added to decrypted.
+func decryptYAML(keys *keyRing, node *yaml.Node, structType reflect.Type, path []string, decrypted map[string]bool) error {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, item := range node.Content {
+			if err := decryptYAML(keys, item, structType, path, decrypted); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := decryptYAML(keys, node.Content[i+1], structType, append(path[:len(path):len(path)], node.Content[i].Value), decrypted); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if !isEncrypted(node.Value) {
+			return nil
+		}
+		plain, err := keys.decrypt(node.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", strings.Join(path, "."), err)
+		}
+		kind, fieldPath := documentField(structType, "yaml", path)
+		decrypted[fieldPath] = true
+		node.Value, node.Tag = plain, "!!str"
+		if kind != reflect.String && kind != reflect.Interface {
+			node.Tag, node.Style = "", 0
+		}
+	}
+	return nil
+}
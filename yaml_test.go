@@ -0,0 +1,69 @@
+package easyconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestYAMLDocumentSourceLoader(t *testing.T) {
+	t.Run("YAMLSource.Load", func(t *testing.T) {
+		config := new(Config)
+		if err := (YAMLSource{"tests/config.multi.yaml"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresHost != "db.prod" || config.PostgresPort != 6432 || config.PostgresDBName != "db-name" {
+			t.Errorf("Config = %+v, want %s", config, "db.prod 6432 db-name")
+		}
+		if config.PostgresUser != "postgres" || strings.Join(config.Slice, ",") != "a1,a2" {
+			t.Errorf("Config = %+v, want %s", config, "postgres [a1 a2]")
+		}
+	})
+
+	t.Run("YAMLDocumentSource.Load", func(t *testing.T) {
+		config := new(Config)
+		if err := (YAMLDocumentSource{Path: "tests/config.multi.yaml", Key: "profile", Value: "dev"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresHost != "dev.local" || config.PostgresPort != 5432 || config.PostgresDBName != "db-name" {
+			t.Errorf("Config = %+v, want %s", config, "dev.local 5432 db-name")
+		}
+
+		config = new(Config)
+		if err := (YAMLDocumentSource{Path: "tests/config.multi.yaml", Key: "profile", Value: "staging"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresHost != "db.prod" || config.PostgresPort != 6432 {
+			t.Errorf("Config = %+v, want %s", config, "db.prod 6432")
+		}
+
+		config = new(Config)
+		if err := (YAMLDocumentSource{Path: "tests/config.multi.yaml", Key: "profile"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresHost != "localhost" {
+			t.Errorf("PostgresHost = %s, want %s", config.PostgresHost, "localhost")
+		}
+	})
+
+	t.Run("ProfileSource.Load", func(t *testing.T) {
+		config := new(Config)
+		if err := (ProfileSource{Path: "tests/config.multi.yaml", Prefix: "APP", Profile: "dev"}).Load(config); err != nil {
+			t.Fatalf("Error = %s, want %s", err.Error(), "nil")
+		}
+		if config.PostgresHost != "dev.local" {
+			t.Errorf("PostgresHost = %s, want %s", config.PostgresHost, "dev.local")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		data := []byte("postgresUser: postgres\n---\nprofile: dev\npostgresPort: dev\n---\nprofile: prod\npostgresPort: prod\n")
+		config := new(Config)
+		err := (YAMLDocumentSource{Path: "config.yaml", Key: "profile", Value: "prod"}).decode(nil, data, config)
+		if err == nil || !strings.Contains(err.Error(), "line 7:") {
+			t.Errorf("Error = %v, want error on line 7", err)
+		}
+		if config.PostgresUser != "" {
+			t.Errorf("PostgresUser = %s, want %s", config.PostgresUser, "")
+		}
+	})
+}